@@ -7,10 +7,12 @@ import (
 
 	prompt "github.com/plandex-ai/go-prompt"
 	"github.com/plandex-ai/go-prompt/completer"
+	istrings "github.com/plandex-ai/go-prompt/strings"
 )
 
 var filePathCompleter = completer.FilePathCompleter{
-	IgnoreCase: true,
+	IgnoreCase:   true,
+	Descriptions: true,
 	Filter: func(fi os.FileInfo) bool {
 		return fi.IsDir() || strings.HasSuffix(fi.Name(), ".go")
 	},
@@ -20,14 +22,15 @@ func executor(in string) {
 	fmt.Println("Your input: " + in)
 }
 
-func completerFunc(d prompt.Document) []prompt.Suggest {
+func completerFunc(d prompt.Document) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
 	t := d.GetWordBeforeCursor()
 	if strings.HasPrefix(t, "--") {
+		end := d.CurrentRuneIndex()
 		return []prompt.Suggest{
-			{"--foo", ""},
-			{"--bar", ""},
-			{"--baz", ""},
-		}
+			{Text: "--foo", Description: ""},
+			{Text: "--bar", Description: ""},
+			{Text: "--baz", Description: ""},
+		}, end - istrings.RuneCountInString(t), end
 	}
 	return filePathCompleter.Complete(d)
 }
@@ -35,9 +38,8 @@ func completerFunc(d prompt.Document) []prompt.Suggest {
 func main() {
 	p := prompt.New(
 		executor,
-		completerFunc,
+		prompt.WithCompleter(completerFunc),
 		prompt.WithPrefix(">>> "),
-		prompt.WithCompletionWordSeparator(completer.FilePathCompletionSeparator),
 	)
 	p.Run()
 }
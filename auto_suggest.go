@@ -0,0 +1,95 @@
+package prompt
+
+import (
+	"strings"
+	"unicode"
+
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// AutoSuggester is a function that returns a suggestion
+// of the text that may follow the cursor, like fish shell does.
+// The suggestion is displayed after the input in a dim color
+// and doesn't become a part of it until it's accepted.
+type AutoSuggester func(Document) (suggestion string)
+
+// AutoSuggest returns the rest of the newest history entry
+// that starts with the text of the document.
+// It can be used as an AutoSuggester.
+func (h *History) AutoSuggest(d Document) string {
+	text := d.Text
+	if text == "" {
+		return ""
+	}
+	for i := len(h.histories) - 1; i >= 0; i-- {
+		if entry := h.histories[i]; len(entry) > len(text) && strings.HasPrefix(entry, text) {
+			return entry[len(text):]
+		}
+	}
+	return ""
+}
+
+// Returns the suggestion that should be displayed after the input.
+// Suggestions are displayed only when the cursor is at the end of the input.
+func (p *Prompt) autoSuggestion() string {
+	if p.autoSuggester == nil || p.search != nil || p.completion.Completing() {
+		return ""
+	}
+	d := p.buffer.Document()
+	if d.cursorPosition < istrings.RuneCountInString(d.Text) {
+		return ""
+	}
+	return p.autoSuggester(*d)
+}
+
+// Handles the keys that accept the displayed suggestion.
+// Right, End and Ctrl+F accept the whole suggestion,
+// Alt+F and Alt+Right accept its next word.
+func (p *Prompt) handleAutoSuggestionKey(key Key) bool {
+	switch key {
+	case Right, End, ControlF, AltRight:
+	default:
+		return false
+	}
+	suggestion := p.autoSuggestion()
+	if suggestion == "" {
+		return false
+	}
+	if key == AltRight {
+		suggestion = firstWord(suggestion)
+	}
+	p.buffer.InsertTextMoveCursor(suggestion, p.renderer.UserInputColumns(), p.renderer.row, false)
+	return true
+}
+
+// Returns the beginning of the text up to the end of its first word
+// including the whitespace before it.
+func firstWord(text string) string {
+	inWord := false
+	for i, char := range text {
+		if unicode.IsSpace(char) {
+			if inWord {
+				return text[:i]
+			}
+			continue
+		}
+		inWord = true
+	}
+	return text
+}
+
+// Cuts the suggestion so that it fits in the rest of the last line of the text.
+func fitAutoSuggestion(text, suggestion string, columns istrings.Width) string {
+	if i := strings.IndexByte(suggestion, '\n'); i != -1 {
+		suggestion = suggestion[:i]
+	}
+	available := columns - positionAtEndOfString(text, columns).X - 1
+	var width istrings.Width
+	for i, char := range suggestion {
+		width += istrings.GetRuneWidth(char)
+		if width > available {
+			return suggestion[:i]
+		}
+	}
+	return suggestion
+}
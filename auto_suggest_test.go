@@ -0,0 +1,111 @@
+package prompt
+
+import (
+	"testing"
+)
+
+func TestHistoryAutoSuggestion(t *testing.T) {
+	p := newTestPrompt(
+		WithHistory([]string{"git commit -m fix", "git status", "go test ./..."}),
+		WithHistoryAutoSuggestion(),
+	)
+	if got := p.autoSuggestion(); got != "" {
+		t.Errorf("Want no suggestion for an empty input, but got %q", got)
+	}
+
+	feedAll(p, "git")
+	if got := p.autoSuggestion(); got != " status" {
+		t.Errorf("Want %q, but got %q", " status", got)
+	}
+	feedAll(p, " c")
+	if got := p.autoSuggestion(); got != "ommit -m fix" {
+		t.Errorf("Want %q, but got %q", "ommit -m fix", got)
+	}
+	if got := p.buffer.Text(); got != "git c" {
+		t.Errorf("The suggestion should not be a part of the text, but got %q", got)
+	}
+
+	// Alt+F accepts the next word
+	feedAll(p, "\x1bf")
+	if got := p.buffer.Text(); got != "git commit" {
+		t.Errorf("Want %q, but got %q", "git commit", got)
+	}
+	feedAll(p, "\x1bf")
+	if got := p.buffer.Text(); got != "git commit -m" {
+		t.Errorf("Want %q, but got %q", "git commit -m", got)
+	}
+
+	// no suggestion when the cursor isn't at the end
+	feedAll(p, "\x1b[D")
+	if got := p.autoSuggestion(); got != "" {
+		t.Errorf("Want no suggestion, but got %q", got)
+	}
+	feedAll(p, "\x05")
+
+	// Right accepts the whole suggestion
+	feedAll(p, "\x1b[C")
+	if got := p.buffer.Text(); got != "git commit -m fix" {
+		t.Errorf("Want %q, but got %q", "git commit -m fix", got)
+	}
+	if got := p.autoSuggestion(); got != "" {
+		t.Errorf("Want no suggestion, but got %q", got)
+	}
+}
+
+func TestHistoryAutoSuggestionDuringSearch(t *testing.T) {
+	p := newTestPrompt(
+		WithHistory([]string{"git status"}),
+		WithHistoryAutoSuggestion(),
+	)
+	feedAll(p, "gi")
+	p.render()
+	if got := p.renderer.autoSuggestion; got != "t status" {
+		t.Errorf("Want %q, but got %q", "t status", got)
+	}
+
+	// the suggestion of the input before the search isn't displayed
+	feedAll(p, "\x12", "ls")
+	p.render()
+	if got := p.renderer.autoSuggestion; got != "" {
+		t.Errorf("Want no suggestion, but got %q", got)
+	}
+}
+
+func TestAutoSuggester(t *testing.T) {
+	p := newTestPrompt(WithAutoSuggester(func(d Document) string {
+		if d.Text == "SEL" {
+			return "ECT * FROM"
+		}
+		return ""
+	}))
+	feedAll(p, "SEL", "\x06")
+	if got := p.buffer.Text(); got != "SELECT * FROM" {
+		t.Errorf("Want %q, but got %q", "SELECT * FROM", got)
+	}
+
+	// Ctrl+F moves the cursor when there's nothing to accept
+	feedAll(p, "\x01", "\x06")
+	if got := p.buffer.cursorPosition; got != 1 {
+		t.Errorf("Want cursor 1, but got %d", got)
+	}
+}
+
+func TestFitAutoSuggestion(t *testing.T) {
+	tests := []struct {
+		text       string
+		suggestion string
+		want       string
+	}{
+		{text: "foo", suggestion: "bar", want: "bar"},
+		{text: "foo", suggestion: "bar\nbaz", want: "bar"},
+		{text: "foo", suggestion: "barbazqux", want: "barbaz"},
+		{text: "foo", suggestion: "日本語", want: "日本語"},
+		{text: "fooo", suggestion: "日本語", want: "日本"},
+		{text: "foo\nbar", suggestion: "bazqux", want: "bazqux"},
+	}
+	for _, tc := range tests {
+		if got := fitAutoSuggestion(tc.text, tc.suggestion, 10); got != tc.want {
+			t.Errorf("fitAutoSuggestion(%q, %q): want %q, but got %q", tc.text, tc.suggestion, tc.want, got)
+		}
+	}
+}
@@ -17,8 +17,31 @@ type Buffer struct {
 	cacheDocument   *Document
 	preferredColumn istrings.Width // Remember the original column for the next up/down movement.
 	lastKeyStroke   Key
+
+	undoStack      []bufferState
+	redoStack      []bufferState
+	lastEdit       editKind
+	lastEditCursor istrings.RuneNumber // cursor position after the last edit
+	editDepth      int                 // number of edits in progress, nested edits are recorded as one step
+	editRecorded   bool                // whether the current edit pushed a state to the undo stack
+}
+
+// Snapshot of the buffer stored in the edit history.
+type bufferState struct {
+	text   string
+	cursor istrings.RuneNumber
 }
 
+// Kind of an edit. Consecutive edits of the same kind
+// (other than editOther) are undone in a single step.
+type editKind uint8
+
+const (
+	editOther      editKind = iota
+	editTyping              // insertion of a single character
+	editCompletion          // insertion of a suggestion by the completion menu
+)
+
 // Text returns string of the current line.
 func (b *Buffer) Text() string {
 	return b.workingLines[b.workingIndex]
@@ -64,6 +87,13 @@ func (b *Buffer) InsertText(text string, overwrite bool) {
 
 // insertText insert string from current line.
 func (b *Buffer) insertText(text string, columns istrings.Width, rows int, overwrite bool, moveCursor bool) {
+	kind := editOther
+	if moveCursor && !overwrite && istrings.RuneCountInString(text) == 1 {
+		kind = editTyping
+	}
+	b.beginEdit(kind)
+	defer b.endEdit()
+
 	currentTextRunes := []rune(b.Text())
 	cursor := b.cursorPosition
 
@@ -191,6 +221,8 @@ func (b *Buffer) CursorDown(count int, columns istrings.Width, rows int) bool {
 // Deletes the specified number of graphemes before the cursor and returns the deleted text.
 func (b *Buffer) DeleteBeforeCursor(count istrings.GraphemeNumber, columns istrings.Width, rows int) string {
 	debug.Assert(count >= 0, "count should be positive")
+	b.beginEdit(editOther)
+	defer b.endEdit()
 	if b.cursorPosition < 0 {
 		return ""
 	}
@@ -223,6 +255,8 @@ func (b *Buffer) DeleteBeforeCursor(count istrings.GraphemeNumber, columns istri
 // Deletes the specified number of runes before the cursor and returns the deleted text.
 func (b *Buffer) DeleteBeforeCursorRunes(count istrings.RuneNumber, columns istrings.Width, rows int) (deleted string) {
 	debug.Assert(count >= 0, "count should be positive")
+	b.beginEdit(editOther)
+	defer b.endEdit()
 	if b.cursorPosition <= 0 {
 		return ""
 	}
@@ -257,6 +291,8 @@ func (b *Buffer) NewLine(columns istrings.Width, rows int, copyMargin bool) {
 
 // Deletes the specified number of graphemes and returns the deleted text.
 func (b *Buffer) Delete(count istrings.GraphemeNumber, col istrings.Width, row int) string {
+	b.beginEdit(editOther)
+	defer b.endEdit()
 	textUtf8 := utf8string.NewString(b.Text())
 	if b.cursorPosition >= istrings.RuneCountInString(b.Text()) {
 		return ""
@@ -279,6 +315,8 @@ func (b *Buffer) Delete(count istrings.GraphemeNumber, col istrings.Width, row i
 
 // Deletes the specified number of runes and returns the deleted text.
 func (b *Buffer) DeleteRunes(count istrings.RuneNumber, col istrings.Width, row int) string {
+	b.beginEdit(editOther)
+	defer b.endEdit()
 	r := []rune(b.Text())
 	if b.cursorPosition < istrings.RuneNumber(len(r)) {
 		textAfterCursor := b.Document().TextAfterCursor()
@@ -299,6 +337,8 @@ func (b *Buffer) DeleteRunes(count istrings.RuneNumber, col istrings.Width, row
 
 // JoinNextLine joins the next line to the current one by deleting the line ending after the current line.
 func (b *Buffer) JoinNextLine(separator string, col istrings.Width, row int) {
+	b.beginEdit(editOther)
+	defer b.endEdit()
 	if !b.Document().OnLastLine() {
 		b.cursorPosition += b.Document().GetEndOfLinePosition()
 		b.Delete(1, col, row)
@@ -313,6 +353,8 @@ func (b *Buffer) JoinNextLine(separator string, col istrings.Width, row int) {
 
 // SwapCharactersBeforeCursor swaps the last two characters before the cursor.
 func (b *Buffer) SwapCharactersBeforeCursor(col istrings.Width, row int) {
+	b.beginEdit(editOther)
+	defer b.endEdit()
 	if b.cursorPosition >= 2 {
 		x := b.Text()[b.cursorPosition-2 : b.cursorPosition-1]
 		y := b.Text()[b.cursorPosition-1 : b.cursorPosition]
@@ -324,6 +366,90 @@ func (b *Buffer) SwapCharactersBeforeCursor(col istrings.Width, row int) {
 	}
 }
 
+// Undo reverts the last edit of the buffer.
+// Returns true when the buffer has been changed.
+func (b *Buffer) Undo(columns istrings.Width, rows int) bool {
+	if len(b.undoStack) == 0 {
+		return false
+	}
+	state := b.undoStack[len(b.undoStack)-1]
+	b.undoStack = b.undoStack[:len(b.undoStack)-1]
+	b.redoStack = append(b.redoStack, b.state())
+	b.restoreState(state, columns, rows)
+	return true
+}
+
+// Redo reapplies the last edit reverted by Undo.
+// Returns true when the buffer has been changed.
+func (b *Buffer) Redo(columns istrings.Width, rows int) bool {
+	if len(b.redoStack) == 0 {
+		return false
+	}
+	state := b.redoStack[len(b.redoStack)-1]
+	b.redoStack = b.redoStack[:len(b.redoStack)-1]
+	b.undoStack = append(b.undoStack, b.state())
+	b.restoreState(state, columns, rows)
+	return true
+}
+
+func (b *Buffer) state() bufferState {
+	return bufferState{
+		text:   b.Text(),
+		cursor: b.cursorPosition,
+	}
+}
+
+func (b *Buffer) restoreState(state bufferState, columns istrings.Width, rows int) {
+	b.cursorPosition = 0
+	b.setText(state.text, columns, rows)
+	b.setCursorPosition(state.cursor)
+	b.recalculateStartLine(columns, rows)
+	b.lastEdit = editOther
+}
+
+// Saves the state of the buffer before an edit so that it can be undone.
+// Consecutive edits of the same kind made at the cursor position
+// left by the previous one are grouped into a single step.
+// Every call has to be followed by a call to endEdit.
+func (b *Buffer) beginEdit(kind editKind) {
+	b.editDepth++
+	if b.editDepth > 1 {
+		return
+	}
+
+	b.editRecorded = false
+	if kind != editOther && kind == b.lastEdit && b.cursorPosition == b.lastEditCursor {
+		return
+	}
+	b.undoStack = append(b.undoStack, b.state())
+	b.editRecorded = true
+	b.lastEdit = kind
+}
+
+func (b *Buffer) endEdit() {
+	b.editDepth--
+	if b.editDepth > 0 {
+		return
+	}
+
+	if b.editRecorded && b.undoStack[len(b.undoStack)-1].text == b.Text() {
+		// nothing has changed
+		b.undoStack = b.undoStack[:len(b.undoStack)-1]
+		return
+	}
+	if b.editRecorded {
+		b.redoStack = nil
+	}
+	b.lastEditCursor = b.cursorPosition
+}
+
+// Forget the edit history.
+func (b *Buffer) clearEditHistory() {
+	b.undoStack = nil
+	b.redoStack = nil
+	b.lastEdit = editOther
+}
+
 // NewBuffer is constructor of Buffer struct.
 func NewBuffer() (b *Buffer) {
 	b = &Buffer{
@@ -249,3 +249,49 @@ func TestBuffer_SwapCharactersBeforeCursor(t *testing.T) {
 		t.Errorf("Should be %#v, got %#v", ex, ac)
 	}
 }
+
+func TestBuffer_UndoRedo(t *testing.T) {
+	b := NewBuffer()
+	for _, char := range "foo bar" {
+		b.InsertTextMoveCursor(string(char), DefColCount, DefRowCount, false)
+	}
+	b.DeleteBeforeCursorRunes(3, DefColCount, DefRowCount)
+	b.InsertTextMoveCursor("baz", DefColCount, DefRowCount, false)
+
+	steps := []string{"foo ", "foo bar", ""}
+	for _, want := range steps {
+		if !b.Undo(DefColCount, DefRowCount) {
+			t.Fatalf("Undo should change the buffer")
+		}
+		if b.Text() != want {
+			t.Errorf("Text should be %#v, got %#v", want, b.Text())
+		}
+	}
+	if b.Undo(DefColCount, DefRowCount) {
+		t.Errorf("Undo should not change an unedited buffer")
+	}
+
+	b.Redo(DefColCount, DefRowCount)
+	if b.Text() != "foo bar" {
+		t.Errorf("Text should be %#v, got %#v", "foo bar", b.Text())
+	}
+	if b.cursorPosition != 7 {
+		t.Errorf("cursorPosition should be %#v, got %#v", 7, b.cursorPosition)
+	}
+
+	// a new edit discards the redo history
+	b.InsertTextMoveCursor("!", DefColCount, DefRowCount, false)
+	if b.Redo(DefColCount, DefRowCount) {
+		t.Errorf("Redo should not be possible after a new edit")
+	}
+}
+
+func TestBuffer_UndoIgnoresEditsWithoutChanges(t *testing.T) {
+	b := NewBuffer()
+	b.InsertTextMoveCursor("foo", DefColCount, DefRowCount, false)
+	b.Delete(1, DefColCount, DefRowCount)
+	b.Undo(DefColCount, DefRowCount)
+	if b.Text() != "" {
+		t.Errorf("Text should be %#v, got %#v", "", b.Text())
+	}
+}
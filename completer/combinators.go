@@ -0,0 +1,139 @@
+package completer
+
+import (
+	"regexp"
+	"strings"
+
+	prompt "github.com/plandex-ai/go-prompt"
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// Merge returns a completer that displays the suggestions of all completers.
+// When their ranges of the replaced text differ, the suggestions are extended
+// with the text between their range and the widest one, so that any of them
+// can replace the widest range.
+func Merge(completers ...prompt.Completer) prompt.Completer {
+	return func(d prompt.Document) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+		type result struct {
+			suggestions []prompt.Suggest
+			start, end  istrings.RuneNumber
+		}
+		text := []rune(d.Text)
+		var results []result
+		var start, end istrings.RuneNumber
+		for _, c := range completers {
+			s, st, en := c(d)
+			if len(s) == 0 || st < 0 || st > en || int(en) > len(text) {
+				continue
+			}
+			if len(results) == 0 || st < start {
+				start = st
+			}
+			if len(results) == 0 || en > end {
+				end = en
+			}
+			results = append(results, result{suggestions: s, start: st, end: en})
+		}
+		if len(results) == 0 {
+			return nil, 0, 0
+		}
+
+		var suggestions []prompt.Suggest
+		for _, r := range results {
+			if r.start == start && r.end == end {
+				suggestions = append(suggestions, r.suggestions...)
+				continue
+			}
+			before := string(text[start:r.start])
+			after := string(text[r.end:end])
+			for _, s := range r.suggestions {
+				s.InsertText = before + s.InsertedText() + after
+				// the cursor stays where it would be without the text after the range
+				s.CursorOffset -= istrings.RuneCountInString(after)
+				suggestions = append(suggestions, s)
+			}
+		}
+		return suggestions, start, end
+	}
+}
+
+// FirstNonEmpty returns a completer that returns the suggestions
+// of the first completer that suggests anything.
+func FirstNonEmpty(completers ...prompt.Completer) prompt.Completer {
+	return func(d prompt.Document) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+		for _, c := range completers {
+			if s, start, end := c(d); len(s) > 0 {
+				return s, start, end
+			}
+		}
+		return nil, 0, 0
+	}
+}
+
+// ByArgIndex returns a completer that dispatches by the index
+// of the shell word under the cursor: the first completer completes
+// the first word, the second one the second word and so on.
+// The last completer completes all the remaining words,
+// nil completers suggest nothing.
+//
+//	ByArgIndex(commands.Complete, files.Complete)
+func ByArgIndex(completers ...prompt.Completer) prompt.Completer {
+	return func(d prompt.Document) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+		if len(completers) == 0 {
+			return nil, 0, 0
+		}
+		i := d.CurrentShellWord().Index
+		if i >= len(completers) {
+			i = len(completers) - 1
+		}
+		if completers[i] == nil {
+			return nil, 0, 0
+		}
+		return completers[i](d)
+	}
+}
+
+// ByPrefix returns a completer that is triggered when the input starts with the prefix.
+// The completer gets a document of the text between the prefix and the cursor,
+// which makes it possible to complete a shell escape of a REPL:
+//
+//	ByPrefix("!", ByArgIndex(executables.Complete, files.Complete))
+func ByPrefix(prefix string, c prompt.Completer) prompt.Completer {
+	return func(d prompt.Document) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+		text := d.TextBeforeCursor()
+		if !strings.HasPrefix(text, prefix) {
+			return nil, 0, 0
+		}
+		offset := istrings.RuneCountInString(prefix)
+		s, start, end := c(*prompt.NewDocumentWithText(text[len(prefix):]))
+		return s, start + offset, end + offset
+	}
+}
+
+// ByRegexp returns a completer that is triggered when the text
+// before the cursor matches the regular expression.
+//
+//	ByRegexp(regexp.MustCompile(`\s--\s`), files.Complete)
+func ByRegexp(re *regexp.Regexp, c prompt.Completer) prompt.Completer {
+	return func(d prompt.Document) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+		if !re.MatchString(d.TextBeforeCursor()) {
+			return nil, 0, 0
+		}
+		return c(d)
+	}
+}
+
+// WithFilter returns a completer that filters the suggestions of c
+// by the unquoted text they replace, e.g. with prompt.FilterFuzzy.
+// c should return all of its suggestions.
+func WithFilter(c prompt.Completer, filter prompt.Filter, ignoreCase bool) prompt.Completer {
+	return func(d prompt.Document) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+		s, start, end := c(d)
+		text := []rune(d.Text)
+		if start < 0 || start > end || int(end) > len(text) {
+			return filter(s, "", ignoreCase), start, end
+		}
+		typed := prompt.NewDocumentWithText(string(text[start:end])).CurrentShellWord().Value
+		return filter(s, typed, ignoreCase), start, end
+	}
+}
@@ -0,0 +1,214 @@
+package completer
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	prompt "github.com/plandex-ai/go-prompt"
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// Returns a completer that returns the suggestions with the given range.
+func rangeCompleter(start, end istrings.RuneNumber, suggestions ...prompt.Suggest) prompt.Completer {
+	return func(prompt.Document) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+		return suggestions, start, end
+	}
+}
+
+// Returns a completer that suggests the given text for the document it receives.
+func echoCompleter(d prompt.Document) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+	w := d.CurrentShellWord()
+	return []prompt.Suggest{{Text: d.Text}}, w.Start, w.End
+}
+
+func TestMerge(t *testing.T) {
+	tests := map[string]struct {
+		completers []prompt.Completer
+		want       []prompt.Suggest
+		wantStart  istrings.RuneNumber
+		wantEnd    istrings.RuneNumber
+	}{
+		"equal ranges": {
+			completers: []prompt.Completer{
+				rangeCompleter(5, 8, prompt.Suggest{Text: "$HOME"}),
+				rangeCompleter(5, 8, prompt.Suggest{Text: "$HOST", CursorOffset: -1}),
+			},
+			want:      []prompt.Suggest{{Text: "$HOME"}, {Text: "$HOST", CursorOffset: -1}},
+			wantStart: 5,
+			wantEnd:   8,
+		},
+		"later start gets the text before it": {
+			completers: []prompt.Completer{
+				rangeCompleter(5, 8, prompt.Suggest{Text: "$HOME"}),
+				rangeCompleter(6, 8, prompt.Suggest{Text: "HOST", InsertText: "HOST}"}),
+			},
+			want:      []prompt.Suggest{{Text: "$HOME"}, {Text: "HOST", InsertText: "$HOST}"}},
+			wantStart: 5,
+			wantEnd:   8,
+		},
+		"earlier end gets the text after it and keeps the cursor": {
+			completers: []prompt.Completer{
+				rangeCompleter(6, 7, prompt.Suggest{Text: "f()", CursorOffset: -1}),
+				rangeCompleter(5, 8, prompt.Suggest{Text: "$HOME"}),
+			},
+			want:      []prompt.Suggest{{Text: "f()", InsertText: "$f()O", CursorOffset: -2}, {Text: "$HOME"}},
+			wantStart: 5,
+			wantEnd:   8,
+		},
+		"empty and invalid results are skipped": {
+			completers: []prompt.Completer{
+				rangeCompleter(0, 0),
+				rangeCompleter(7, 5, prompt.Suggest{Text: "reversed"}),
+				rangeCompleter(5, 100, prompt.Suggest{Text: "out of text"}),
+				rangeCompleter(6, 8, prompt.Suggest{Text: "HOME"}),
+			},
+			want:      []prompt.Suggest{{Text: "HOME"}},
+			wantStart: 6,
+			wantEnd:   8,
+		},
+		"nothing": {
+			completers: []prompt.Completer{rangeCompleter(0, 0)},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			suggestions, start, end := Merge(tc.completers...)(*prompt.NewDocumentWithText("echo $HO"))
+			if !reflect.DeepEqual(tc.want, suggestions) {
+				t.Errorf("Want %#v, but got %#v", tc.want, suggestions)
+			}
+			if start != tc.wantStart || end != tc.wantEnd {
+				t.Errorf("Want range %d-%d, but got %d-%d", tc.wantStart, tc.wantEnd, start, end)
+			}
+		})
+	}
+}
+
+func TestFirstNonEmpty(t *testing.T) {
+	c := FirstNonEmpty(
+		rangeCompleter(0, 0),
+		rangeCompleter(1, 2, prompt.Suggest{Text: "second"}),
+		rangeCompleter(0, 2, prompt.Suggest{Text: "third"}),
+	)
+	suggestions, start, end := c(*prompt.NewDocumentWithText("ab"))
+	if want := []string{"second"}; !reflect.DeepEqual(want, suggestionTexts(suggestions)) {
+		t.Errorf("Want %#v, but got %#v", want, suggestionTexts(suggestions))
+	}
+	if start != 1 || end != 2 {
+		t.Errorf("Want range 1-2, but got %d-%d", start, end)
+	}
+}
+
+func TestByArgIndex(t *testing.T) {
+	c := ByArgIndex(
+		rangeCompleter(0, 0, prompt.Suggest{Text: "command"}),
+		nil,
+		rangeCompleter(0, 0, prompt.Suggest{Text: "rest"}),
+	)
+	tests := map[string][]string{
+		"":              {"command"},
+		"cmd":           {"command"},
+		"cmd ":          nil,
+		`cmd "a b" `:    {"rest"},
+		"cmd a b c d":   {"rest"},
+		`cmd a\ b c`:    {"rest"},
+		`cmd "a b" c d`: {"rest"},
+	}
+	for text, want := range tests {
+		suggestions, _, _ := c(*prompt.NewDocumentWithText(text))
+		if got := suggestionTexts(suggestions); !reflect.DeepEqual(want, got) {
+			t.Errorf("%q: want %#v, but got %#v", text, want, got)
+		}
+	}
+}
+
+func TestByPrefix(t *testing.T) {
+	tests := map[string]struct {
+		prefix    string
+		text      string
+		want      []string
+		wantStart istrings.RuneNumber
+		wantEnd   istrings.RuneNumber
+	}{
+		"shell escape": {
+			prefix:    "!",
+			text:      "!ls fo",
+			want:      []string{"ls fo"},
+			wantStart: 4,
+			wantEnd:   6,
+		},
+		"multi-byte prefix": {
+			prefix:    "λ ",
+			text:      "λ ü",
+			want:      []string{"ü"},
+			wantStart: 2,
+			wantEnd:   3,
+		},
+		"other input": {
+			prefix: "!",
+			text:   "ls !fo",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			suggestions, start, end := ByPrefix(tc.prefix, echoCompleter)(*prompt.NewDocumentWithText(tc.text))
+			if got := suggestionTexts(suggestions); !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("Want %#v, but got %#v", tc.want, got)
+			}
+			if tc.want != nil && (start != tc.wantStart || end != tc.wantEnd) {
+				t.Errorf("Want range %d-%d, but got %d-%d", tc.wantStart, tc.wantEnd, start, end)
+			}
+		})
+	}
+}
+
+func TestByRegexp(t *testing.T) {
+	c := ByRegexp(regexp.MustCompile(`\s--\s`), echoCompleter)
+	if suggestions, _, _ := c(*prompt.NewDocumentWithText("git checkout -- fi")); len(suggestions) != 1 {
+		t.Errorf("Want 1 suggestion, but got %#v", suggestions)
+	}
+	if suggestions, _, _ := c(*prompt.NewDocumentWithText("git checkout fi")); suggestions != nil {
+		t.Errorf("Want no suggestions, but got %#v", suggestions)
+	}
+}
+
+func TestWithFilter(t *testing.T) {
+	all := []prompt.Suggest{{Text: "foo bar"}, {Text: "foobar"}, {Text: "baz"}}
+	tests := map[string]struct {
+		completer prompt.Completer
+		text      string
+		want      []string
+	}{
+		"quoted word": {
+			completer: rangeCompleter(4, 10, all...),
+			text:      `cat "foo b`,
+			want:      []string{"foo bar"},
+		},
+		"escaped word": {
+			completer: rangeCompleter(4, 10, all...),
+			text:      `cat foo\ b`,
+			want:      []string{"foo bar"},
+		},
+		"unquoted word": {
+			completer: rangeCompleter(4, 7, all...),
+			text:      "cat foo",
+			want:      []string{"foo bar", "foobar"},
+		},
+		"invalid range": {
+			completer: rangeCompleter(4, 100, all...),
+			text:      "cat fo",
+			want:      []string{"foo bar", "foobar", "baz"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			suggestions, _, _ := WithFilter(tc.completer, prompt.FilterFuzzy, false)(*prompt.NewDocumentWithText(tc.text))
+			if got := suggestionTexts(suggestions); !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("Want %#v, but got %#v", tc.want, got)
+			}
+		})
+	}
+}
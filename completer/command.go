@@ -0,0 +1,296 @@
+package completer
+
+import (
+	"strings"
+
+	prompt "github.com/plandex-ai/go-prompt"
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// FlagType is the type of the value of a Flag.
+type FlagType uint8
+
+const (
+	// FlagBool is a flag without a value.
+	FlagBool FlagType = iota
+	// FlagString is a flag with a string value.
+	FlagString
+	// FlagInt is a flag with an integer value.
+	FlagInt
+	// FlagFloat is a flag with a floating point value.
+	FlagFloat
+)
+
+// String returns the placeholder of the value displayed in the suggestions.
+func (t FlagType) String() string {
+	switch t {
+	case FlagString:
+		return "string"
+	case FlagInt:
+		return "int"
+	case FlagFloat:
+		return "float"
+	}
+	return "bool"
+}
+
+// Flag describes a flag of a Command like "--output=file" or "-o file".
+type Flag struct {
+	Name        string // long name without the dashes, e.g. "output"
+	Short       string // optional short name without the dash, e.g. "o"
+	Description string
+	Type        FlagType
+	// Values are the allowed values of the flag.
+	Values []string
+	// Completer completes the value of the flag when Values is empty.
+	// It receives a document containing only the value.
+	Completer prompt.Completer
+	// Repeatable flags are suggested even when they have already been used.
+	Repeatable bool
+}
+
+// Returns the suggestions of the long and the short form of the flag.
+func (f *Flag) suggestions() []prompt.Suggest {
+	var suggestions []prompt.Suggest
+	for _, name := range []string{"--" + f.Name, "-" + f.Short} {
+		if name == "--" || name == "-" {
+			continue
+		}
+		s := prompt.Suggest{Text: name, Description: f.Description, Kind: prompt.SuggestKindFlag}
+		if f.Type != FlagBool {
+			separator := " "
+			if strings.HasPrefix(name, "--") {
+				separator = "="
+			}
+			s.DisplayText = name + separator + "<" + f.Type.String() + ">"
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions
+}
+
+// Command describes a command or a subcommand
+// whose arguments get completed by CommandCompleter.
+type Command struct {
+	Name        string
+	Aliases     []string
+	Description string
+	Subcommands []*Command
+	Flags       []*Flag
+	// Args complete the positional arguments by their index.
+	Args []prompt.Completer
+	// RestArgs completes the positional arguments after the ones of Args.
+	RestArgs prompt.Completer
+}
+
+// Whether the command accepts positional arguments
+// (which can be mixed with its subcommands).
+func (c *Command) hasArgs() bool {
+	return len(c.Args) > 0 || c.RestArgs != nil
+}
+
+func (c *Command) matches(name string, ignoreCase bool) bool {
+	for _, n := range append([]string{c.Name}, c.Aliases...) {
+		if n == name || ignoreCase && strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// CommandCompleter completes command lines described by a tree of commands.
+// It knows which command and which argument the cursor is on,
+// completes the values of flags given as "--flag value" or "--flag=value"
+// and stops suggesting flags that have already been used.
+// The command line is split into shell words, so quoted
+// and escaped arguments are supported.
+//
+//	c := &completer.CommandCompleter{Commands: []*completer.Command{...}}
+//	p := prompt.New(executor, prompt.WithCompleter(c.Complete))
+type CommandCompleter struct {
+	Commands []*Command
+	// Flags are accepted by all commands.
+	Flags      []*Flag
+	IgnoreCase bool
+	// Filter filters the suggestions of commands, flags and Values,
+	// prompt.FilterHasPrefix is used when it is nil.
+	Filter prompt.Filter
+}
+
+// state of a command line parsed up to the word under the cursor
+type commandLine struct {
+	command    *Command // nil at the top level
+	used       map[*Flag]bool
+	pending    *Flag // flag waiting for its value in the next word
+	positional int   // number of positional arguments of the command
+	endOfFlags bool  // whether "--" has been used
+	unknown    bool  // whether an unknown command has been used
+}
+
+// Complete implements prompt.Completer.
+func (c *CommandCompleter) Complete(d prompt.Document) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+	words := d.ShellWordsBeforeCursor()
+	current := words[len(words)-1]
+	values := make([]string, len(words)-1)
+	for i, w := range words[:len(words)-1] {
+		values[i] = w.Value
+	}
+
+	line := c.parse(values)
+	if line.unknown {
+		return nil, 0, 0
+	}
+
+	if line.pending != nil {
+		return c.completeFlagValue(line.pending, current.Raw, current.Start)
+	}
+	if !line.endOfFlags && strings.HasPrefix(current.Value, "-") {
+		if i := strings.IndexByte(current.Raw, '='); i != -1 {
+			f := c.lookupFlag(line.command, current.Raw[:i])
+			if f == nil {
+				return nil, 0, 0
+			}
+			return c.completeFlagValue(f, current.Raw[i+1:], current.Start+istrings.RuneCountInString(current.Raw[:i+1]))
+		}
+		return c.complete(c.flagSuggestions(line), current)
+	}
+
+	if line.command == nil {
+		return c.complete(commandSuggestions(c.Commands), current)
+	}
+	if len(line.command.Subcommands) > 0 && line.positional == 0 {
+		// the first argument is completed when no subcommand matches
+		suggestions, start, end := c.complete(commandSuggestions(line.command.Subcommands), current)
+		if len(suggestions) > 0 || !line.command.hasArgs() {
+			return suggestions, start, end
+		}
+	}
+	var args prompt.Completer
+	if line.positional < len(line.command.Args) {
+		args = line.command.Args[line.positional]
+	} else {
+		args = line.command.RestArgs
+	}
+	if args == nil {
+		return nil, 0, 0
+	}
+	return args(d)
+}
+
+// Parses the words before the one under the cursor.
+func (c *CommandCompleter) parse(words []string) *commandLine {
+	line := &commandLine{used: make(map[*Flag]bool)}
+	for _, w := range words {
+		switch {
+		case line.pending != nil:
+			line.pending = nil
+		case w == "--" && !line.endOfFlags:
+			line.endOfFlags = true
+		case strings.HasPrefix(w, "-") && !line.endOfFlags:
+			name, _, hasValue := strings.Cut(w, "=")
+			if f := c.lookupFlag(line.command, name); f != nil {
+				line.used[f] = true
+				if f.Type != FlagBool && !hasValue {
+					line.pending = f
+				}
+			}
+		case line.command == nil:
+			line.command = c.lookupCommand(c.Commands, w)
+			if line.command == nil {
+				line.unknown = true
+				return line
+			}
+		case len(line.command.Subcommands) > 0 && line.positional == 0:
+			sub := c.lookupCommand(line.command.Subcommands, w)
+			switch {
+			case sub != nil:
+				line.command = sub
+			case line.command.hasArgs():
+				line.positional++
+			default:
+				line.unknown = true
+				return line
+			}
+		default:
+			line.positional++
+		}
+	}
+	return line
+}
+
+func (c *CommandCompleter) lookupCommand(commands []*Command, name string) *Command {
+	for _, cmd := range commands {
+		if cmd.matches(name, c.IgnoreCase) {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// Returns the flag of the command or a global flag
+// with the given name including the dashes.
+func (c *CommandCompleter) lookupFlag(cmd *Command, name string) *Flag {
+	for _, f := range c.availableFlags(cmd) {
+		if name == "--"+f.Name || f.Short != "" && name == "-"+f.Short {
+			return f
+		}
+	}
+	return nil
+}
+
+// Returns the flags of the command followed by the global ones.
+func (c *CommandCompleter) availableFlags(cmd *Command) []*Flag {
+	var flags []*Flag
+	if cmd != nil {
+		flags = append(flags, cmd.Flags...)
+	}
+	return append(flags, c.Flags...)
+}
+
+func (c *CommandCompleter) flagSuggestions(line *commandLine) []prompt.Suggest {
+	var suggestions []prompt.Suggest
+	for _, f := range c.availableFlags(line.command) {
+		if line.used[f] && !f.Repeatable {
+			continue
+		}
+		suggestions = append(suggestions, f.suggestions()...)
+	}
+	return suggestions
+}
+
+// Completes the value of the flag typed as raw that starts at the given index.
+func (c *CommandCompleter) completeFlagValue(f *Flag, raw string, start istrings.RuneNumber) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+	d := prompt.NewDocumentWithText(raw)
+	if len(f.Values) > 0 {
+		suggestions := make([]prompt.Suggest, len(f.Values))
+		for i, v := range f.Values {
+			suggestions[i] = prompt.Suggest{Text: v}
+		}
+		suggestions, s, e := c.complete(suggestions, d.CurrentShellWord())
+		return suggestions, start + s, start + e
+	}
+	if f.Completer == nil {
+		return nil, 0, 0
+	}
+	suggestions, s, e := f.Completer(*d)
+	return suggestions, start + s, start + e
+}
+
+// Filters the suggestions by the value of the word
+// and quotes them so that they can replace it.
+func (c *CommandCompleter) complete(suggestions []prompt.Suggest, w prompt.ShellWord) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+	if c.Filter == nil {
+		suggestions = prompt.FilterHasPrefix(suggestions, w.Value, c.IgnoreCase)
+	} else {
+		suggestions = c.Filter(suggestions, w.Value, c.IgnoreCase)
+	}
+	return prompt.QuoteSuggestions(suggestions, w), w.Start, w.End
+}
+
+func commandSuggestions(commands []*Command) []prompt.Suggest {
+	suggestions := make([]prompt.Suggest, len(commands))
+	for i, cmd := range commands {
+		suggestions[i] = prompt.Suggest{Text: cmd.Name, Description: cmd.Description, Kind: prompt.SuggestKindCommand}
+	}
+	return suggestions
+}
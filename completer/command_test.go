@@ -0,0 +1,175 @@
+package completer
+
+import (
+	"reflect"
+	"testing"
+
+	prompt "github.com/plandex-ai/go-prompt"
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// Returns a completer that suggests the given text for the word under the cursor.
+func staticCompleter(text string) prompt.Completer {
+	return func(d prompt.Document) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+		word := d.GetWordBeforeCursor()
+		end := d.CurrentRuneIndex()
+		return []prompt.Suggest{{Text: text}}, end - istrings.RuneCountInString(word), end
+	}
+}
+
+func suggestionTexts(suggestions []prompt.Suggest) []string {
+	var texts []string
+	for _, s := range suggestions {
+		texts = append(texts, s.InsertedText())
+	}
+	return texts
+}
+
+func TestCommandCompleter(t *testing.T) {
+	c := &CommandCompleter{
+		Commands: []*Command{
+			{
+				Name: "git",
+				Subcommands: []*Command{
+					{
+						Name:    "commit",
+						Aliases: []string{"ci"},
+						Flags: []*Flag{
+							{Name: "message", Short: "m", Type: FlagString},
+							{Name: "amend"},
+						},
+					},
+					{
+						Name:     "checkout",
+						Args:     []prompt.Completer{staticCompleter("branch")},
+						RestArgs: staticCompleter("file"),
+					},
+				},
+			},
+			{
+				Name:        "task",
+				Subcommands: []*Command{{Name: "add"}, {Name: "done"}},
+				Args:        []prompt.Completer{staticCompleter("filter")},
+				RestArgs:    staticCompleter("modification"),
+			},
+		},
+		Flags: []*Flag{
+			{Name: "verbose", Short: "v", Repeatable: true},
+			{Name: "color", Type: FlagString, Values: []string{"always", "auto", "never", "on demand"}},
+		},
+	}
+
+	tests := map[string]struct {
+		text      string
+		want      []string
+		wantStart istrings.RuneNumber
+	}{
+		"command": {
+			text:      "gi",
+			want:      []string{"git"},
+			wantStart: 0,
+		},
+		"subcommands": {
+			text:      "git c",
+			want:      []string{"commit", "checkout"},
+			wantStart: 4,
+		},
+		"flags of a subcommand given by its alias": {
+			text:      "git ci --",
+			want:      []string{"--message", "--amend", "--verbose", "--color"},
+			wantStart: 7,
+		},
+		"short flags": {
+			text:      "git commit -",
+			want:      []string{"--message", "-m", "--amend", "--verbose", "-v", "--color"},
+			wantStart: 11,
+		},
+		"used flags are hidden": {
+			text:      "git commit --amend -m msg --",
+			want:      []string{"--verbose", "--color"},
+			wantStart: 26,
+		},
+		"repeatable flags are kept": {
+			text:      "git commit -v --v",
+			want:      []string{"--verbose"},
+			wantStart: 14,
+		},
+		"value after an equal sign": {
+			text:      "git commit --color=a",
+			want:      []string{"always", "auto"},
+			wantStart: 19,
+		},
+		"pending value": {
+			text:      "git commit --color ",
+			want:      []string{"always", "auto", "never", `on\ demand`},
+			wantStart: 19,
+		},
+		"quoted pending value": {
+			text:      `git commit --color "on`,
+			want:      []string{`"on demand"`},
+			wantStart: 19,
+		},
+		"pending value without values": {
+			text: "git commit --message ",
+		},
+		"first positional argument": {
+			text:      "git checkout --color always ",
+			want:      []string{"branch"},
+			wantStart: 28,
+		},
+		"rest of the positional arguments": {
+			text:      "git checkout main ",
+			want:      []string{"file"},
+			wantStart: 18,
+		},
+		"flags after the terminator are arguments": {
+			text:      "git checkout -- -",
+			want:      []string{"branch"},
+			wantStart: 16,
+		},
+		"argument after the terminator": {
+			text:      "git checkout -- -f ",
+			want:      []string{"file"},
+			wantStart: 19,
+		},
+		"subcommands mixed with arguments": {
+			text:      "task d",
+			want:      []string{"done"},
+			wantStart: 5,
+		},
+		"argument instead of a subcommand": {
+			text:      "task 1",
+			want:      []string{"filter"},
+			wantStart: 5,
+		},
+		"argument after an argument instead of a subcommand": {
+			text:      "task 12 ",
+			want:      []string{"modification"},
+			wantStart: 8,
+		},
+		"unknown command": {
+			text: "svn ",
+		},
+		"unknown subcommand": {
+			text: "git push ",
+		},
+		"unknown flag with a value": {
+			text: "git commit --unknown=",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			suggestions, start, end := c.Complete(*prompt.NewDocumentWithText(tc.text))
+			if got := suggestionTexts(suggestions); !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("Want %#v, but got %#v", tc.want, got)
+			}
+			if tc.want == nil {
+				return
+			}
+			if start != tc.wantStart || end != istrings.RuneCountInString(tc.text) {
+				t.Errorf("Want range %d-%d, but got %d-%d", tc.wantStart, istrings.RuneCountInString(tc.text), start, end)
+			}
+		})
+	}
+}
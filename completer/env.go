@@ -0,0 +1,168 @@
+package completer
+
+import (
+	"bufio"
+	"os"
+	"os/user"
+	"runtime"
+	"sort"
+	"strings"
+
+	runewidth "github.com/mattn/go-runewidth"
+	prompt "github.com/plandex-ai/go-prompt"
+	"github.com/plandex-ai/go-prompt/debug"
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// maximal width of the values of environment variables displayed as descriptions
+const envValueMaxWidth = 40
+
+// text displayed in place of masked values
+const maskedValue = "********"
+
+// EnvCompleter completes the names of environment variables after "$" or "${"
+// describing them with their values, and the home directories of users after "~".
+// It returns no suggestions for other words, so it can be tried
+// in front of FilePathCompleter:
+//
+//	FirstNonEmpty(env.Complete, files.Complete)
+type EnvCompleter struct {
+	IgnoreCase bool
+	// Mask reports whether the value of the variable should be hidden,
+	// values are displayed when it is nil. See MaskSecrets.
+	Mask func(name string) bool
+
+	homes map[string]string // home directories of users, loaded on the first use
+}
+
+// MaskSecrets reports whether the name of an environment variable
+// looks like it holds a secret, like GITHUB_TOKEN or DB_PASSWORD.
+// It can be used as EnvCompleter.Mask.
+func MaskSecrets(name string) bool {
+	name = strings.ToUpper(name)
+	for _, s := range []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "KEY", "CREDENTIAL"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Complete implements prompt.Completer.
+func (c *EnvCompleter) Complete(d prompt.Document) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+	word := d.CurrentShellWord()
+	if word.Quote == prompt.QuoteSingle {
+		// nothing gets expanded inside single quotes
+		return nil, 0, 0
+	}
+
+	if i, braced, ok := variableStart(word.Raw); ok {
+		start := word.Start + istrings.RuneCountInString(word.Raw[:i])
+		return c.variableSuggestions(word.Raw[i:], braced), start, word.End
+	}
+	if strings.HasPrefix(word.Raw, "~") && !strings.ContainsRune(word.Raw, '/') {
+		return c.homeSuggestions(word.Raw[1:]), word.Start, word.End
+	}
+	return nil, 0, 0
+}
+
+// Returns the byte index of the name of the variable the raw word ends with,
+// braced is true for "${name".
+func variableStart(raw string) (i int, braced, ok bool) {
+	i = len(raw)
+	for i > 0 && isVariableNameByte(raw[i-1]) {
+		i--
+	}
+	switch {
+	case i >= 2 && raw[i-2:i] == "${":
+		return i, true, true
+	case i >= 1 && raw[i-1] == '$':
+		return i, false, true
+	}
+	return 0, false, false
+}
+
+func isVariableNameByte(b byte) bool {
+	return b == '_' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9'
+}
+
+func (c *EnvCompleter) variableSuggestions(prefix string, braced bool) []prompt.Suggest {
+	environ := os.Environ()
+	suggestions := make([]prompt.Suggest, 0, len(environ))
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if name == "" || strings.HasPrefix(name, "=") {
+			// Windows has hidden variables like "=C:"
+			continue
+		}
+
+		if c.Mask != nil && c.Mask(name) {
+			value = maskedValue
+		} else {
+			value = runewidth.Truncate(strings.ReplaceAll(value, "\n", " "), envValueMaxWidth, "...")
+		}
+		s := prompt.Suggest{Text: name, Description: value, Kind: prompt.SuggestKindVariable}
+		if braced {
+			s.InsertText = name + "}"
+		}
+		suggestions = append(suggestions, s)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Text < suggestions[j].Text
+	})
+	return prompt.FilterHasPrefix(suggestions, prefix, c.IgnoreCase)
+}
+
+func (c *EnvCompleter) homeSuggestions(prefix string) []prompt.Suggest {
+	if c.homes == nil {
+		c.homes = readHomeDirectories()
+	}
+
+	suggestions := make([]prompt.Suggest, 0, len(c.homes))
+	for name, home := range c.homes {
+		suggestions = append(suggestions, prompt.Suggest{
+			Text:        "~" + name + "/",
+			DisplayText: "~" + name,
+			Description: home,
+			Kind:        prompt.SuggestKindDirectory,
+		})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Text < suggestions[j].Text
+	})
+	return prompt.FilterHasPrefix(suggestions, "~"+prefix, c.IgnoreCase)
+}
+
+// Returns the home directories of the users by their names read from /etc/passwd.
+// Home directories are not completed on Windows.
+func readHomeDirectories() map[string]string {
+	homes := make(map[string]string)
+	if runtime.GOOS == "windows" {
+		return homes
+	}
+	if me, err := user.Current(); err == nil {
+		homes[me.Username] = me.HomeDir
+	}
+
+	f, err := os.Open("/etc/passwd")
+	if err != nil {
+		debug.Log("completer: cannot read users:" + err.Error())
+		return homes
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		// name:password:uid:gid:gecos:home:shell
+		fields := strings.Split(line, ":")
+		if len(fields) < 7 || fields[0] == "" || fields[5] == "" {
+			continue
+		}
+		homes[fields[0]] = fields[5]
+	}
+	return homes
+}
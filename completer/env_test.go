@@ -0,0 +1,142 @@
+package completer
+
+import (
+	"os/user"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	prompt "github.com/plandex-ai/go-prompt"
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+func TestEnvCompleter(t *testing.T) {
+	t.Setenv("GOPROMPT_TEST_VAR", "value")
+	t.Setenv("GOPROMPT_TEST_TOKEN", "secret")
+	t.Setenv("GOPROMPT_TEST_LONG", strings.Repeat("x", 50))
+
+	tests := map[string]struct {
+		completer *EnvCompleter
+		text      string
+		want      []prompt.Suggest
+		wantStart istrings.RuneNumber
+	}{
+		"variable": {
+			completer: &EnvCompleter{},
+			text:      "echo $GOPROMPT_TEST_V",
+			want:      []prompt.Suggest{{Text: "GOPROMPT_TEST_VAR", Description: "value", Kind: prompt.SuggestKindVariable}},
+			wantStart: 6,
+		},
+		"braced variable": {
+			completer: &EnvCompleter{},
+			text:      "echo ${GOPROMPT_TEST_V",
+			want:      []prompt.Suggest{{Text: "GOPROMPT_TEST_VAR", Description: "value", InsertText: "GOPROMPT_TEST_VAR}", Kind: prompt.SuggestKindVariable}},
+			wantStart: 7,
+		},
+		"variable inside a word": {
+			completer: &EnvCompleter{},
+			text:      `cd "a/$GOPROMPT_TEST_V`,
+			want:      []prompt.Suggest{{Text: "GOPROMPT_TEST_VAR", Description: "value", Kind: prompt.SuggestKindVariable}},
+			wantStart: 7,
+		},
+		"ignore case": {
+			completer: &EnvCompleter{IgnoreCase: true},
+			text:      "echo $goprompt_test_v",
+			want:      []prompt.Suggest{{Text: "GOPROMPT_TEST_VAR", Description: "value", Kind: prompt.SuggestKindVariable}},
+			wantStart: 6,
+		},
+		"masked value": {
+			completer: &EnvCompleter{Mask: MaskSecrets},
+			text:      "echo $GOPROMPT_TEST_T",
+			want:      []prompt.Suggest{{Text: "GOPROMPT_TEST_TOKEN", Description: maskedValue, Kind: prompt.SuggestKindVariable}},
+			wantStart: 6,
+		},
+		"unmasked value": {
+			completer: &EnvCompleter{},
+			text:      "echo $GOPROMPT_TEST_T",
+			want:      []prompt.Suggest{{Text: "GOPROMPT_TEST_TOKEN", Description: "secret", Kind: prompt.SuggestKindVariable}},
+			wantStart: 6,
+		},
+		"truncated value": {
+			completer: &EnvCompleter{},
+			text:      "echo $GOPROMPT_TEST_L",
+			want:      []prompt.Suggest{{Text: "GOPROMPT_TEST_LONG", Description: strings.Repeat("x", 37) + "...", Kind: prompt.SuggestKindVariable}},
+			wantStart: 6,
+		},
+		"nothing in single quotes": {
+			completer: &EnvCompleter{},
+			text:      "echo '$GOPROMPT_TEST_V",
+		},
+		"nothing without a dollar": {
+			completer: &EnvCompleter{},
+			text:      "echo GOPROMPT_TEST_V",
+		},
+		"home directory": {
+			completer: &EnvCompleter{homes: map[string]string{"alice": "/home/alice", "bob": "/home/bob"}},
+			text:      "ls ~al",
+			want:      []prompt.Suggest{{Text: "~alice/", DisplayText: "~alice", Description: "/home/alice", Kind: prompt.SuggestKindDirectory}},
+			wantStart: 3,
+		},
+		"nothing after a home directory": {
+			completer: &EnvCompleter{homes: map[string]string{"alice": "/home/alice"}},
+			text:      "ls ~alice/",
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			suggestions, start, end := tc.completer.Complete(*prompt.NewDocumentWithText(tc.text))
+			if !reflect.DeepEqual(tc.want, suggestions) {
+				t.Errorf("Want %#v, but got %#v", tc.want, suggestions)
+			}
+			if tc.want == nil {
+				return
+			}
+			if start != tc.wantStart || end != istrings.RuneCountInString(tc.text) {
+				t.Errorf("Want range %d-%d, but got %d-%d", tc.wantStart, istrings.RuneCountInString(tc.text), start, end)
+			}
+		})
+	}
+}
+
+func TestMaskSecrets(t *testing.T) {
+	tests := map[string]bool{
+		"GITHUB_TOKEN":   true,
+		"DB_PASSWORD":    true,
+		"aws_secret_key": true,
+		"HOME":           false,
+		"PATH":           false,
+	}
+	for name, want := range tests {
+		if got := MaskSecrets(name); got != want {
+			t.Errorf("%s: want %v, but got %v", name, want, got)
+		}
+	}
+}
+
+func TestCleanFilePathHomeDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("home directories are not expanded on Windows")
+	}
+	me, err := user.Current()
+	if err != nil {
+		t.Skipf("cannot get the current user: %s", err)
+	}
+
+	// the home directories suggested by EnvCompleter are expanded
+	for _, path := range []string{"~/foo", "~" + me.Username + "/foo"} {
+		dir, base, err := cleanFilePath(path)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if dir != filepath.Clean(me.HomeDir) || base != "foo" {
+			t.Errorf("%s: want %q and %q, but got %q and %q", path, filepath.Clean(me.HomeDir), "foo", dir, base)
+		}
+	}
+
+	if _, _, err := cleanFilePath("~no-such-user-of-go-prompt/foo"); err == nil {
+		t.Errorf("Want an error for an unknown user")
+	}
+}
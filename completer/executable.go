@@ -0,0 +1,154 @@
+package completer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	prompt "github.com/plandex-ai/go-prompt"
+	"github.com/plandex-ai/go-prompt/debug"
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// ExecutableCompleter completes the names of the executable files
+// in the directories of $PATH, describing them with their full paths.
+// Names shadowed by executables in earlier $PATH directories are suggested once.
+// It completes the shell word under the cursor, combine it with other completers
+// to complete only the first word of the input.
+type ExecutableCompleter struct {
+	IgnoreCase bool
+
+	path        string                   // value of $PATH the suggestions have been collected for
+	dirs        map[string]executableDir // executables of every directory of $PATH
+	suggestions []prompt.Suggest
+}
+
+// executable files of a directory
+type executableDir struct {
+	modTime time.Time // modification time of the directory when it has been read
+	names   []string
+}
+
+// Complete returns the executables whose names start with the word under the cursor.
+// It implements prompt.Completer.
+func (c *ExecutableCompleter) Complete(d prompt.Document) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+	word := d.CurrentShellWord()
+	suggestions := prompt.FilterHasPrefix(c.executables(), word.Value, c.IgnoreCase)
+	if runtime.GOOS != "windows" {
+		suggestions = prompt.QuoteSuggestions(suggestions, word)
+	}
+	return suggestions, word.Start, word.End
+}
+
+// Returns the suggestions of all executables on $PATH.
+// They are collected again when $PATH or one of its directories has been modified.
+func (c *ExecutableCompleter) executables() []prompt.Suggest {
+	if c.dirs == nil {
+		c.dirs = make(map[string]executableDir)
+	}
+
+	path := os.Getenv("PATH")
+	changed := path != c.path
+	dirs := filepath.SplitList(path)
+	seenDirs := make(map[string]bool, len(dirs))
+	for _, dir := range dirs {
+		if dir == "" || seenDirs[dir] {
+			continue
+		}
+		seenDirs[dir] = true
+
+		info, err := os.Stat(dir)
+		if err != nil {
+			if _, ok := c.dirs[dir]; ok {
+				delete(c.dirs, dir)
+				changed = true
+			}
+			continue
+		}
+		if cached, ok := c.dirs[dir]; ok && cached.modTime.Equal(info.ModTime()) {
+			continue
+		}
+		c.dirs[dir] = executableDir{modTime: info.ModTime(), names: readExecutables(dir)}
+		changed = true
+	}
+	if !changed {
+		return c.suggestions
+	}
+
+	// forget the directories removed from $PATH
+	for dir := range c.dirs {
+		if !seenDirs[dir] {
+			delete(c.dirs, dir)
+		}
+	}
+
+	seen := make(map[string]bool)
+	suggestions := make([]prompt.Suggest, 0, len(c.suggestions))
+	for _, dir := range dirs {
+		for _, name := range c.dirs[dir].names {
+			key := name
+			if runtime.GOOS == "windows" {
+				key = strings.ToLower(name)
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			suggestions = append(suggestions, prompt.Suggest{
+				Text:        name,
+				Description: filepath.Join(dir, name),
+				Kind:        prompt.SuggestKindCommand,
+			})
+		}
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Text < suggestions[j].Text
+	})
+	c.path = path
+	c.suggestions = suggestions
+	return suggestions
+}
+
+// Returns the names of the executable files in the directory.
+func readExecutables(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		debug.Log("completer: cannot read directory items:" + err.Error())
+		return nil
+	}
+
+	var names []string
+	for _, e := range entries {
+		// follow symbolic links
+		info, err := os.Stat(filepath.Join(dir, e.Name()))
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if isExecutable(info) {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+// Reports whether the file is executable, on Windows it depends on its extension.
+func isExecutable(info os.FileInfo) bool {
+	if runtime.GOOS != "windows" {
+		return info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0
+	}
+
+	pathExt := os.Getenv("PATHEXT")
+	if pathExt == "" {
+		pathExt = ".COM;.EXE;.BAT;.CMD"
+	}
+	ext := filepath.Ext(info.Name())
+	for _, e := range filepath.SplitList(pathExt) {
+		if e != "" && strings.EqualFold(e, ext) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package completer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	prompt "github.com/plandex-ai/go-prompt"
+)
+
+// Creates executable files with the given names in the directory.
+func writeExecutables(t *testing.T, dir string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0700); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+}
+
+func TestExecutableCompleter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executables are recognized by their extensions on Windows")
+	}
+	first := newTestDir(t, "not-executable", "subdir/")
+	second := t.TempDir()
+	writeExecutables(t, first, "foo")
+	writeExecutables(t, second, "foo", "bar")
+	t.Setenv("PATH", strings.Join([]string{first, second, first}, string(os.PathListSeparator)))
+
+	// executables of earlier directories shadow the later ones
+	c := &ExecutableCompleter{}
+	want := []prompt.Suggest{
+		{Text: "bar", Description: filepath.Join(second, "bar"), Kind: prompt.SuggestKindCommand},
+		{Text: "foo", Description: filepath.Join(first, "foo"), Kind: prompt.SuggestKindCommand},
+	}
+	if got := c.executables(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Want %#v, but got %#v", want, got)
+	}
+
+	// nothing gets read again while $PATH and its directories are unmodified
+	c.suggestions = []prompt.Suggest{{Text: "cached"}}
+	if got := c.executables(); len(got) != 1 || got[0].Text != "cached" {
+		t.Errorf("Want the cached suggestions, but got %#v", got)
+	}
+
+	// the modified directory gets read again
+	writeExecutables(t, second, "baz")
+	modTime := time.Now().Add(time.Minute)
+	if err := os.Chtimes(second, modTime, modTime); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	want = []prompt.Suggest{
+		{Text: "bar", Description: filepath.Join(second, "bar"), Kind: prompt.SuggestKindCommand},
+		{Text: "baz", Description: filepath.Join(second, "baz"), Kind: prompt.SuggestKindCommand},
+		{Text: "foo", Description: filepath.Join(first, "foo"), Kind: prompt.SuggestKindCommand},
+	}
+	if got := c.executables(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Want %#v, but got %#v", want, got)
+	}
+
+	// a new $PATH changes the shadowing
+	t.Setenv("PATH", second)
+	want = []prompt.Suggest{
+		{Text: "bar", Description: filepath.Join(second, "bar"), Kind: prompt.SuggestKindCommand},
+		{Text: "baz", Description: filepath.Join(second, "baz"), Kind: prompt.SuggestKindCommand},
+		{Text: "foo", Description: filepath.Join(second, "foo"), Kind: prompt.SuggestKindCommand},
+	}
+	if got := c.executables(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Want %#v, but got %#v", want, got)
+	}
+	if _, ok := c.dirs[first]; ok {
+		t.Errorf("Want the directory removed from $PATH to be forgotten")
+	}
+
+	suggestions, _, _ := c.Complete(*prompt.NewDocumentWithText("ba"))
+	if len(suggestions) != 2 {
+		t.Errorf("Want 2 suggestions, but got %#v", suggestions)
+	}
+}
@@ -1,13 +1,17 @@
 package completer
 
 import (
+	"fmt"
 	"os"
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"time"
 
 	prompt "github.com/plandex-ai/go-prompt"
 	"github.com/plandex-ai/go-prompt/debug"
+	istrings "github.com/plandex-ai/go-prompt/strings"
 )
 
 var (
@@ -16,12 +20,32 @@ var (
 )
 
 // FilePathCompleter is a completer for your local file system.
-// Please caution that you need to set WithCompletionWordSeparator(completer.FilePathCompletionSeparator)
-// when you use this completer.
+// The path under the cursor is read as a shell word, so quoted paths
+// and paths with escaped spaces are completed and the inserted text
+// gets quoted or escaped the same way, while the typed directory
+// is kept as it is. Directories are suggested with a trailing separator
+// and their quotes are left open.
 type FilePathCompleter struct {
-	Filter        func(fi os.FileInfo) bool
-	IgnoreCase    bool
-	fileListCache map[string][]prompt.Suggest
+	Filter     func(fi os.FileInfo) bool
+	IgnoreCase bool
+	// Patterns are glob patterns (see filepath.Match) the names of the suggested files
+	// have to match, directories are always suggested.
+	Patterns []string
+	// HideHidden makes the files starting with a dot suggested
+	// only when the typed name starts with a dot.
+	HideHidden bool
+	// Descriptions makes the suggestions describe the type and the size of files
+	// and the targets of symbolic links.
+	Descriptions bool
+
+	fileListCache map[string]fileList
+}
+
+// suggestions of the files of a directory without descriptions,
+// which depend on the files themselves
+type fileList struct {
+	modTime     time.Time // modification time of the directory when it has been read
+	suggestions []prompt.Suggest
 }
 
 func cleanFilePath(path string) (dir, base string, err error) {
@@ -29,65 +53,229 @@ func cleanFilePath(path string) (dir, base string, err error) {
 		return ".", "", nil
 	}
 
-	var endsWithSeparator bool
-	if len(path) >= 1 && path[len(path)-1] == os.PathSeparator {
-		endsWithSeparator = true
-	}
-
-	if runtime.GOOS != "windows" && len(path) >= 2 && path[0:2] == "~/" {
-		me, err := user.Current()
+	// "~/" and "~name/" are home directories
+	if i := strings.IndexByte(path, '/'); runtime.GOOS != "windows" && i > 0 && path[0] == '~' {
+		home, err := homeDir(path[1:i])
 		if err != nil {
 			return "", "", err
 		}
-		path = filepath.Join(me.HomeDir, path[1:])
+		path = home + path[i:]
 	}
-	path = filepath.Clean(os.ExpandEnv(path))
-	dir = filepath.Dir(path)
-	base = filepath.Base(path)
+	path = os.ExpandEnv(path)
 
-	if endsWithSeparator {
-		dir = path + string(os.PathSeparator) // Append slash(in POSIX) if path ends with slash.
-		base = ""                             // Set empty string if path ends with separator.
+	// the base is kept as it is typed, filepath.Clean would drop a lone dot
+	i := len(path) - 1
+	for i >= len(filepath.VolumeName(path)) && !os.IsPathSeparator(path[i]) {
+		i--
 	}
-	return dir, base, nil
+	return filepath.Clean(path[:i+1]), path[i+1:], nil
+}
+
+// Returns the home directory of the user with the given name,
+// of the current user when the name is empty.
+func homeDir(name string) (string, error) {
+	var u *user.User
+	var err error
+	if name == "" {
+		u, err = user.Current()
+	} else {
+		u, err = user.Lookup(name)
+	}
+	if err != nil {
+		return "", err
+	}
+	return u.HomeDir, nil
 }
 
 // Complete returns suggestions from your local file system.
-func (c *FilePathCompleter) Complete(d prompt.Document) []prompt.Suggest {
+// It implements prompt.Completer.
+func (c *FilePathCompleter) Complete(d prompt.Document) ([]prompt.Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+	word := d.CurrentShellWord()
+	if runtime.GOOS == "windows" {
+		// backslashes are path separators on Windows, not escapes
+		path := d.GetWordBeforeCursor()
+		end := d.CurrentRuneIndex()
+		word = prompt.ShellWord{Value: path, Raw: path, Start: end - istrings.RuneCountInString(path), End: end}
+	}
+	dir, base, err := cleanFilePath(word.Value)
+	if err != nil {
+		debug.Log("completer: cannot get home directory:" + err.Error())
+		return nil, 0, 0
+	}
+
+	files := c.readDir(dir)
+	if c.HideHidden && !strings.HasPrefix(base, ".") {
+		visible := make([]prompt.Suggest, 0, len(files))
+		for _, f := range files {
+			if !strings.HasPrefix(f.Text, ".") {
+				visible = append(visible, f)
+			}
+		}
+		files = visible
+	}
+	files = prompt.FilterHasPrefix(files, base, c.IgnoreCase)
+
+	// the typed directory is kept as it is, only the name gets replaced
+	typedDir := word.Raw[:strings.LastIndexByte(word.Raw, os.PathSeparator)+1]
+	suggestions := make([]prompt.Suggest, len(files))
+	for i, f := range files {
+		if c.Descriptions {
+			f.Description = describeFile(filepath.Join(dir, f.Text))
+		}
+		if runtime.GOOS == "windows" {
+			f.InsertText = typedDir + f.Text
+		} else {
+			f.InsertText = typedDir + quoteFileName(f.Text, typedDir, word.Raw[len(typedDir):])
+		}
+		// leave the quotes of directories open to continue completing their files,
+		// the next completion closes them
+		if f.Kind == prompt.SuggestKindDirectory && (strings.HasSuffix(f.InsertText, `/"`) || strings.HasSuffix(f.InsertText, `/'`)) {
+			f.InsertText = f.InsertText[:len(f.InsertText)-1]
+		}
+		suggestions[i] = f
+	}
+	return suggestions, word.Start, word.End
+}
+
+// Returns the name quoted or escaped the way the typed name is,
+// inside the quotes left open by the typed directory if there are some.
+func quoteFileName(name, typedDir, typedName string) string {
+	if words := prompt.NewDocumentWithText(typedDir).ShellWords(); len(words) > 0 {
+		if quote := words[len(words)-1].Quote; quote != prompt.QuoteNone {
+			// drop the opening quote
+			return prompt.ShellQuote(name, quote)[1:]
+		}
+	}
+	quote := prompt.QuoteNone
+	switch {
+	case strings.HasPrefix(typedName, "'"):
+		quote = prompt.QuoteSingle
+	case strings.HasPrefix(typedName, `"`):
+		quote = prompt.QuoteDouble
+	}
+	return prompt.ShellQuote(name, quote)
+}
+
+// Returns the suggestions of the files in the directory,
+// they are read again when the directory has been modified.
+func (c *FilePathCompleter) readDir(dir string) []prompt.Suggest {
 	if c.fileListCache == nil {
-		c.fileListCache = make(map[string][]prompt.Suggest, 4)
+		c.fileListCache = make(map[string]fileList, 4)
 	}
 
-	path := d.GetWordBeforeCursor()
-	dir, base, err := cleanFilePath(path)
+	info, err := os.Stat(dir)
 	if err != nil {
-		debug.Log("completer: cannot get current user:" + err.Error())
+		if !os.IsNotExist(err) {
+			debug.Log("completer: cannot stat directory:" + err.Error())
+		}
 		return nil
 	}
-
-	if cached, ok := c.fileListCache[dir]; ok {
-		return prompt.FilterHasPrefix(cached, base, c.IgnoreCase)
+	if cached, ok := c.fileListCache[dir]; ok && cached.modTime.Equal(info.ModTime()) {
+		return cached.suggestions
 	}
 
 	files, err := os.ReadDir(dir)
-	if err != nil && os.IsNotExist(err) {
-		return nil
-	} else if err != nil {
+	if err != nil {
 		debug.Log("completer: cannot read directory items:" + err.Error())
 		return nil
 	}
+	suggests := c.fileSuggestions(dir, files)
+	c.fileListCache[dir] = fileList{modTime: info.ModTime(), suggestions: suggests}
+	return suggests
+}
 
+// Returns the suggestions of the given entries of the directory.
+func (c *FilePathCompleter) fileSuggestions(dir string, files []os.DirEntry) []prompt.Suggest {
 	suggests := make([]prompt.Suggest, 0, len(files))
 	for _, f := range files {
 		fileInfo, err := f.Info()
 		if err != nil {
-			panic(err)
+			// the file may have been removed in the meantime
+			debug.Log("completer: cannot get file info:" + err.Error())
+			continue
 		}
 		if c.Filter != nil && !c.Filter(fileInfo) {
 			continue
 		}
-		suggests = append(suggests, prompt.Suggest{Text: f.Name()})
+
+		s := prompt.Suggest{Text: f.Name(), Kind: prompt.SuggestKindFile}
+		target := fileInfo
+		if fileInfo.Mode()&os.ModeSymlink != 0 {
+			if t, err := os.Stat(filepath.Join(dir, f.Name())); err == nil {
+				target = t
+			}
+		}
+		if target.IsDir() {
+			s.Text += string(os.PathSeparator)
+			s.Kind = prompt.SuggestKindDirectory
+		} else if !c.matchesPatterns(f.Name()) {
+			continue
+		}
+		suggests = append(suggests, s)
+	}
+	return suggests
+}
+
+func (c *FilePathCompleter) matchesPatterns(name string) bool {
+	if len(c.Patterns) == 0 {
+		return true
+	}
+	for _, pattern := range c.Patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns the description of a file, read when the completion is requested
+// so that it isn't outdated by changes of the file that don't modify its directory.
+func describeFile(path string) string {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return ""
+	}
+	target := info
+	if info.Mode()&os.ModeSymlink != 0 {
+		if t, err := os.Stat(path); err == nil {
+			target = t
+		}
+	}
+
+	var description string
+	switch {
+	case target.IsDir():
+		description = "directory"
+	case target.Mode()&os.ModeNamedPipe != 0:
+		description = "named pipe"
+	case target.Mode()&os.ModeSocket != 0:
+		description = "socket"
+	case target.Mode()&os.ModeDevice != 0:
+		description = "device"
+	default:
+		description = "file, " + formatFileSize(target.Size())
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		link, err := os.Readlink(path)
+		if err != nil {
+			return "symlink"
+		}
+		return description + ", symlink to " + link
+	}
+	return description
+}
+
+// Returns the size in bytes in a human-readable form, e.g. "1.5 KiB".
+func formatFileSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
 	}
-	c.fileListCache[dir] = suggests
-	return prompt.FilterHasPrefix(suggests, base, c.IgnoreCase)
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
 }
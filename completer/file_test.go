@@ -0,0 +1,249 @@
+package completer
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	prompt "github.com/plandex-ai/go-prompt"
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// Creates the files in a new temporary directory, names ending
+// with a slash are created as directories.
+func newTestDir(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		var err error
+		if name[len(name)-1] == '/' {
+			err = os.MkdirAll(path, 0700)
+		} else {
+			err = os.WriteFile(path, nil, 0600)
+		}
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	return dir
+}
+
+func insertTexts(suggestions []prompt.Suggest) []string {
+	texts := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		texts[i] = s.InsertText
+	}
+	return texts
+}
+
+func TestFilePathCompleterQuoting(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("words are not unquoted on Windows")
+	}
+	dir := newTestDir(t, "My Dir/", "My Dir/file.txt", "other")
+
+	tests := map[string]struct {
+		text      string
+		want      []string
+		wantStart istrings.RuneNumber
+	}{
+		"directory in double quotes is left open": {
+			text:      `cat "` + dir + `/My D`,
+			want:      []string{`"` + dir + `/My Dir/`},
+			wantStart: 4,
+		},
+		"file in double quotes gets closed": {
+			text:      `cat "` + dir + `/My Dir/f`,
+			want:      []string{`"` + dir + `/My Dir/file.txt"`},
+			wantStart: 4,
+		},
+		"file in single quotes gets closed": {
+			text:      `cat '` + dir + `/My Dir/f`,
+			want:      []string{`'` + dir + `/My Dir/file.txt'`},
+			wantStart: 4,
+		},
+		"escaped space": {
+			text:      `cat ` + dir + `/My\ D`,
+			want:      []string{dir + `/My\ Dir/`},
+			wantStart: 4,
+		},
+		"unquoted file": {
+			text:      `cat ` + dir + `/o`,
+			want:      []string{dir + `/other`},
+			wantStart: 4,
+		},
+		"variable": {
+			text:      `cat $TEST_DIR/My\ Dir/f`,
+			want:      []string{`$TEST_DIR/My\ Dir/file.txt`},
+			wantStart: 4,
+		},
+		"variable in braces": {
+			text:      `cat ${TEST_DIR}/My`,
+			want:      []string{`${TEST_DIR}/My\ Dir/`},
+			wantStart: 4,
+		},
+		"variable in double quotes": {
+			text:      `cat "$TEST_DIR/My Dir/f`,
+			want:      []string{`"$TEST_DIR/My Dir/file.txt"`},
+			wantStart: 4,
+		},
+		"quoted name after an escaped directory": {
+			text:      `cat $TEST_DIR/"My`,
+			want:      []string{`$TEST_DIR/"My Dir/`},
+			wantStart: 4,
+		},
+	}
+	t.Setenv("TEST_DIR", dir)
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &FilePathCompleter{}
+			suggestions, start, end := c.Complete(*prompt.NewDocumentWithText(tc.text))
+			if got := insertTexts(suggestions); !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("Want %#v, but got %#v", tc.want, got)
+			}
+			if start != tc.wantStart || end != istrings.RuneCountInString(tc.text) {
+				t.Errorf("Want range %d-%d, but got %d-%d", tc.wantStart, istrings.RuneCountInString(tc.text), start, end)
+			}
+			for _, s := range suggestions {
+				if s.CursorOffset != 0 {
+					t.Errorf("Want no cursor offset, but got %d", s.CursorOffset)
+				}
+			}
+		})
+	}
+}
+
+func TestFilePathCompleterCache(t *testing.T) {
+	dir := newTestDir(t, "a")
+	c := &FilePathCompleter{}
+	if got := c.readDir(dir); len(got) != 1 {
+		t.Fatalf("Want 1 suggestion, but got %#v", got)
+	}
+
+	// the cached suggestions are used while the directory is unmodified
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	c.fileListCache[dir] = fileList{modTime: info.ModTime(), suggestions: []prompt.Suggest{{Text: "cached"}}}
+	if got := c.readDir(dir); len(got) != 1 || got[0].Text != "cached" {
+		t.Errorf("Want the cached suggestion, but got %#v", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "b"), nil, 0600); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	modTime := info.ModTime().Add(time.Second)
+	if err := os.Chtimes(dir, modTime, modTime); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	got := c.readDir(dir)
+	if want := []prompt.Suggest{{Text: "a", Kind: prompt.SuggestKindFile}, {Text: "b", Kind: prompt.SuggestKindFile}}; !reflect.DeepEqual(want, got) {
+		t.Errorf("Want %#v, but got %#v", want, got)
+	}
+}
+
+func TestFilePathCompleterDescriptions(t *testing.T) {
+	dir := newTestDir(t, "file", "sub/")
+	c := &FilePathCompleter{Descriptions: true}
+	describe := func() []string {
+		suggestions, _, _ := c.Complete(*prompt.NewDocumentWithText(dir + string(os.PathSeparator)))
+		descriptions := make([]string, len(suggestions))
+		for i, s := range suggestions {
+			descriptions[i] = s.Description
+		}
+		return descriptions
+	}
+
+	if want, got := []string{"file, 0 B", "directory"}, describe(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Want %#v, but got %#v", want, got)
+	}
+
+	// writing to a file doesn't modify its directory, the size isn't cached
+	if err := os.WriteFile(filepath.Join(dir, "file"), make([]byte, 2048), 0600); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if want, got := []string{"file, 2.0 KiB", "directory"}, describe(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Want %#v, but got %#v", want, got)
+	}
+}
+
+func TestFilePathCompleterFiltering(t *testing.T) {
+	dir := newTestDir(t, ".hidden", "main.go", "README.md", "sub/")
+	sep := string(os.PathSeparator)
+
+	tests := map[string]struct {
+		completer *FilePathCompleter
+		name      string
+		want      []string
+	}{
+		"hidden files are shown": {
+			completer: &FilePathCompleter{},
+			want:      []string{".hidden", "README.md", "main.go", "sub" + sep},
+		},
+		"hidden files are skipped": {
+			completer: &FilePathCompleter{HideHidden: true},
+			want:      []string{"README.md", "main.go", "sub" + sep},
+		},
+		"hidden files are suggested for a dot": {
+			completer: &FilePathCompleter{HideHidden: true},
+			name:      ".",
+			want:      []string{".hidden"},
+		},
+		"patterns keep directories": {
+			completer: &FilePathCompleter{Patterns: []string{"*.go"}},
+			want:      []string{"main.go", "sub" + sep},
+		},
+		"ignore case": {
+			completer: &FilePathCompleter{IgnoreCase: true},
+			name:      "r",
+			want:      []string{"README.md"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := dir + sep + tc.name
+			suggestions, _, _ := tc.completer.Complete(*prompt.NewDocumentWithText(path))
+			got := make([]string, len(suggestions))
+			for i, s := range suggestions {
+				got[i] = s.Text
+			}
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("Want %#v, but got %#v", tc.want, got)
+			}
+		})
+	}
+}
+
+// Entry of a directory that has been removed after the directory was read.
+type removedDirEntry struct {
+	name string
+}
+
+func (e removedDirEntry) Name() string               { return e.name }
+func (e removedDirEntry) IsDir() bool                { return false }
+func (e removedDirEntry) Type() fs.FileMode          { return 0 }
+func (e removedDirEntry) Info() (fs.FileInfo, error) { return nil, errors.New("removed") }
+
+func TestFilePathCompleterSkipsEntriesWithoutInfo(t *testing.T) {
+	dir := newTestDir(t, "kept")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	entries = append(entries, removedDirEntry{name: "removed"})
+
+	c := &FilePathCompleter{}
+	got := c.fileSuggestions(dir, entries)
+	if want := []prompt.Suggest{{Text: "kept", Kind: prompt.SuggestKindFile}}; !reflect.DeepEqual(want, got) {
+		t.Errorf("Want %#v, but got %#v", want, got)
+	}
+}
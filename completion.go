@@ -21,6 +21,72 @@ const (
 type Suggest struct {
 	Text        string
 	Description string
+	// DisplayText is displayed in the auto-complete box in place of Text when not empty.
+	DisplayText string
+	// InsertText is inserted into the buffer in place of Text when not empty.
+	InsertText string
+	// Kind determines the icon displayed next to the suggestion.
+	Kind SuggestKind
+	// TextColor and BGColor override the colors of the suggestion
+	// when it is not selected, DefaultColor keeps the colors of the prompt.
+	TextColor Color
+	BGColor   Color
+	// Group is the name of the section of the auto-complete box
+	// the suggestion is displayed in, suggestions without a group have no header.
+	Group string
+	// CursorOffset moves the cursor after the suggestion has been inserted,
+	// relative to the end of the inserted text.
+	// For example -1 places the cursor inside the parentheses of "foo()".
+	CursorOffset istrings.RuneNumber
+}
+
+// Returns the text displayed in the auto-complete box.
+func (s *Suggest) displayText() string {
+	if s.DisplayText != "" {
+		return s.DisplayText
+	}
+	return s.Text
+}
+
+// InsertedText returns the text inserted into the buffer,
+// InsertText or Text when it is empty.
+func (s *Suggest) InsertedText() string {
+	if s.InsertText != "" {
+		return s.InsertText
+	}
+	return s.Text
+}
+
+// SuggestKind describes what a suggestion completes.
+type SuggestKind uint8
+
+const (
+	// SuggestKindNone displays no icon.
+	SuggestKindNone SuggestKind = iota
+	// SuggestKindCommand is a command or a subcommand.
+	SuggestKindCommand
+	// SuggestKindFlag is a flag or an option of a command.
+	SuggestKindFlag
+	// SuggestKindFile is a path of a file.
+	SuggestKindFile
+	// SuggestKindDirectory is a path of a directory.
+	SuggestKindDirectory
+	// SuggestKindKeyword is a keyword of a language.
+	SuggestKindKeyword
+	// SuggestKindVariable is a name of a variable.
+	SuggestKindVariable
+)
+
+// Returns the icons displayed next to the suggestions of each kind by default.
+func defaultSuggestKindIcons() map[SuggestKind]string {
+	return map[SuggestKind]string{
+		SuggestKindCommand:   ">",
+		SuggestKindFlag:      "-",
+		SuggestKindFile:      "f",
+		SuggestKindDirectory: "/",
+		SuggestKindKeyword:   "k",
+		SuggestKindVariable:  "$",
+	}
 }
 
 // CompletionManager manages which suggestion is now selected.
@@ -32,10 +98,16 @@ type CompletionManager struct {
 	startCharIndex istrings.RuneNumber // index of the first char of the text that should be replaced by the selected suggestion
 	endCharIndex   istrings.RuneNumber // index of the last char of the text that should be replaced by the selected suggestion
 	shouldUpdate   bool
+	query          string // text replaced by the selected suggestion, used to highlight the matched runes
 
 	verticalScroll int
 	wordSeparator  string
 	showAtStart    bool
+	layout         CompletionLayout
+	behavior       CompletionBehavior
+	columns        int                   // number of columns of the grid layout
+	async          *asyncCompletionState // nil when the completer is synchronous
+	loadingText    string                // text displayed while the asynchronous completer is running
 }
 
 // GetSelectedSuggestion returns the selected item.
@@ -60,51 +132,121 @@ func (c *CompletionManager) GetSuggestions() []Suggest {
 func (c *CompletionManager) Reset() {
 	c.selected = -1
 	c.verticalScroll = 0
+	if c.async != nil {
+		c.cancelAsync()
+		c.tmp = nil
+		c.query = ""
+		return
+	}
 	c.Update(*NewDocument())
 }
 
 // Update the suggestions.
+// The asynchronous completer only gets started,
+// its suggestions arrive later.
 func (c *CompletionManager) Update(in Document) {
+	if c.async != nil {
+		c.updateAsync(in)
+		return
+	}
 	c.tmp, c.startCharIndex, c.endCharIndex = c.completer(in)
+	c.tmp = groupSuggestions(c.tmp)
+	c.updateQuery(in)
+}
+
+// Stores the text of the document that gets replaced by the suggestions.
+func (c *CompletionManager) updateQuery(in Document) {
+	text := []rune(in.Text)
+	start, end := c.startCharIndex, c.endCharIndex
+	if start < 0 || start > end || int(end) > len(text) {
+		c.query = ""
+		return
+	}
+	c.query = string(text[start:end])
 }
 
 // Select the previous suggestion item.
 func (c *CompletionManager) Previous() {
-	if c.verticalScroll == c.selected && c.selected > 0 {
-		c.verticalScroll--
-	}
 	c.selected--
 	c.update()
 }
 
 // Next to select the next suggestion item.
 func (c *CompletionManager) Next() int {
-	if c.verticalScroll+int(c.max)-1 == c.selected {
-		c.verticalScroll++
-	}
 	c.selected++
 	c.update()
 	return c.selected
 }
 
+// NextPage selects the suggestion one page below the selected one,
+// stopping at the last suggestion.
+// A page is a screen of rows in the list layout and a screen of cells in the grid layout.
+func (c *CompletionManager) NextPage() {
+	if len(c.tmp) == 0 {
+		return
+	}
+	c.selected += c.pageSize()
+	if c.selected >= len(c.tmp) {
+		c.selected = len(c.tmp) - 1
+	}
+	c.update()
+}
+
+// PreviousPage selects the suggestion one page above the selected one,
+// stopping at the first suggestion.
+func (c *CompletionManager) PreviousPage() {
+	if len(c.tmp) == 0 {
+		return
+	}
+	if c.selected < 0 {
+		c.selected = len(c.tmp)
+	}
+	c.selected -= c.pageSize()
+	if c.selected < 0 {
+		c.selected = 0
+	}
+	c.update()
+}
+
+// First selects the first suggestion.
+func (c *CompletionManager) First() {
+	if len(c.tmp) == 0 {
+		return
+	}
+	c.selected = 0
+	c.update()
+}
+
+// Last selects the last suggestion.
+func (c *CompletionManager) Last() {
+	c.selected = len(c.tmp) - 1
+	c.update()
+}
+
+// Returns the number of suggestions displayed at once, at least 1.
+func (c *CompletionManager) pageSize() int {
+	size := int(c.max)
+	if c.layout == CompletionLayoutGrid {
+		size *= c.gridColumns()
+	}
+	if size < 1 {
+		return 1
+	}
+	return size
+}
+
 // Completing returns true when the CompletionManager selects something.
 func (c *CompletionManager) Completing() bool {
 	return c.selected != -1
 }
 
 func (c *CompletionManager) update() {
-	max := int(c.max)
-	if len(c.tmp) < max {
-		max = len(c.tmp)
-	}
-
 	if c.selected >= len(c.tmp) {
 		c.selected = -1
-		c.verticalScroll = 0
 	} else if c.selected < -1 {
 		c.selected = len(c.tmp) - 1
-		c.verticalScroll = len(c.tmp) - max
 	}
+	c.scrollToSelected()
 }
 
 func deleteBreakLineCharacters(s string) string {
@@ -155,13 +297,23 @@ func formatTexts(o []string, max istrings.Width, prefix, suffix string) (new []s
 	return n, lenPrefix + width + lenSuffix
 }
 
-func formatSuggestions(suggests []Suggest, max istrings.Width) (new []Suggest, width istrings.Width) {
+func formatSuggestions(suggests []Suggest, max istrings.Width, icons map[SuggestKind]string) (new []Suggest, width istrings.Width) {
 	num := len(suggests)
 	new = make([]Suggest, num)
 
+	iconCells := suggestionIconCells(suggests, icons)
 	left := make([]string, num)
 	for i := 0; i < num; i++ {
-		left[i] = suggests[i].Text
+		left[i] = suggests[i].displayText()
+		if iconCells != nil {
+			left[i] = iconCells[i] + left[i]
+		}
+	}
+	// make room for the headers of the groups
+	for _, row := range completionRows(suggests) {
+		if row.isHeader() {
+			left = append(left, row.group)
+		}
 	}
 	right := make([]string, num)
 	for i := 0; i < num; i++ {
@@ -180,6 +332,68 @@ func formatSuggestions(suggests []Suggest, max istrings.Width) (new []Suggest, w
 	return new, istrings.Width(leftWidth + rightWidth)
 }
 
+// Returns the icons of the kinds of the suggestions padded to equal width
+// and followed by a space, nil when none of the suggestions has an icon.
+func suggestionIconCells(suggests []Suggest, icons map[SuggestKind]string) []string {
+	var width istrings.Width
+	for _, s := range suggests {
+		if w := istrings.GetWidth(icons[s.Kind]); w > width {
+			width = w
+		}
+	}
+	if width == 0 {
+		return nil
+	}
+
+	cells := make([]string, len(suggests))
+	for i, s := range suggests {
+		icon := icons[s.Kind]
+		cells[i] = icon + strings.Repeat(" ", int(width-istrings.GetWidth(icon))+1)
+	}
+	return cells
+}
+
+// CompletionBehavior determines what Tab does
+// when no suggestion is selected.
+type CompletionBehavior uint8
+
+const (
+	// CompletionBehaviorCycle selects the first suggestion,
+	// subsequent presses of Tab cycle through the suggestions.
+	CompletionBehaviorCycle CompletionBehavior = iota
+	// CompletionBehaviorCommonPrefix inserts the longest common prefix
+	// of the suggestions like bash does.
+	// When there's nothing to insert Tab cycles through the suggestions.
+	CompletionBehaviorCommonPrefix
+)
+
+// Behavior returns the completion behavior of Tab.
+func (c *CompletionManager) Behavior() CompletionBehavior {
+	return c.behavior
+}
+
+// CommonPrefix returns the longest common prefix
+// of the inserted texts of all suggestions.
+func (c *CompletionManager) CommonPrefix() string {
+	if len(c.tmp) == 0 {
+		return ""
+	}
+	prefix := []rune(c.tmp[0].InsertedText())
+	for _, s := range c.tmp[1:] {
+		text := []rune(s.InsertedText())
+		if len(text) < len(prefix) {
+			prefix = prefix[:len(text)]
+		}
+		for i := range prefix {
+			if prefix[i] != text[i] {
+				prefix = prefix[:i]
+				break
+			}
+		}
+	}
+	return string(prefix)
+}
+
 // Constructor option for CompletionManager.
 type CompletionManagerOption func(*CompletionManager)
 
@@ -190,6 +404,13 @@ func CompletionManagerWithCompleter(completer Completer) CompletionManagerOption
 	}
 }
 
+// CompletionManagerWithBehavior sets the completion behavior of Tab.
+func CompletionManagerWithBehavior(behavior CompletionBehavior) CompletionManagerOption {
+	return func(c *CompletionManager) {
+		c.behavior = behavior
+	}
+}
+
 // NewCompletionManager returns an initialized CompletionManager object.
 func NewCompletionManager(max uint16, opts ...CompletionManagerOption) *CompletionManager {
 	c := &CompletionManager{
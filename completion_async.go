@@ -0,0 +1,179 @@
+package prompt
+
+import (
+	"context"
+	"time"
+
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// DefaultAsyncCompletionLoadingText is the text displayed
+// in the completion box while an AsyncCompleter is running.
+const DefaultAsyncCompletionLoadingText = "loading…"
+
+// AsyncCompleter is a Completer that may take a long time
+// to return the suggestions for the given Document, for example
+// because it runs external commands.
+//
+// It gets called in its own goroutine so typing isn't blocked.
+// ctx is cancelled when the document changes before the suggestions are returned,
+// the suggestions of outdated documents are discarded.
+type AsyncCompleter func(ctx context.Context, d Document) (suggestions []Suggest, startChar, endChar istrings.RuneNumber)
+
+// asyncCompletion is a message sent by the goroutine running the AsyncCompleter.
+type asyncCompletion struct {
+	generation     uint64 // generation of the document the completer has been called for
+	started        bool   // true when the completer has been called, false when it has returned
+	suggestions    []Suggest
+	startCharIndex istrings.RuneNumber
+	endCharIndex   istrings.RuneNumber
+}
+
+// state of the AsyncCompleter of a CompletionManager
+type asyncCompletionState struct {
+	completer  AsyncCompleter
+	debounce   time.Duration // time the document has to stay unchanged before the completer gets called
+	results    chan asyncCompletion
+	generation uint64 // incremented every time the document changes
+	cancel     context.CancelFunc
+	document   *Document // document of the latest request
+	loading    bool
+}
+
+// Set a custom asynchronous completer that gets called after the document
+// hasn't changed for the debounce interval.
+// It's used in place of the synchronous completer.
+func CompletionManagerWithAsyncCompleter(completer AsyncCompleter, debounce time.Duration) CompletionManagerOption {
+	return func(c *CompletionManager) {
+		c.async = &asyncCompletionState{
+			completer: completer,
+			debounce:  debounce,
+			results:   make(chan asyncCompletion, 16),
+		}
+	}
+}
+
+// Set the text displayed in the completion box while
+// the asynchronous completer is running.
+func CompletionManagerWithAsyncLoadingText(text string) CompletionManagerOption {
+	return func(c *CompletionManager) {
+		c.loadingText = text
+	}
+}
+
+// Returns the text displayed in the completion box
+// while the asynchronous completer is running.
+func (c *CompletionManager) asyncLoadingText() string {
+	if c.loadingText == "" {
+		return DefaultAsyncCompletionLoadingText
+	}
+	return c.loadingText
+}
+
+// Loading returns true when the asynchronous completer
+// is running for the current document.
+func (c *CompletionManager) Loading() bool {
+	return c.async != nil && c.async.loading
+}
+
+// Cancels the running completer and starts a new one for the given document.
+// The suggestions are cleared until the new ones arrive.
+func (c *CompletionManager) updateAsync(in Document) {
+	a := c.async
+	if a.document != nil && a.document.Text == in.Text && a.document.cursorPosition == in.cursorPosition {
+		// the suggestions are up to date or are being loaded
+		return
+	}
+	c.cancelAsync()
+	c.tmp = nil
+	c.startCharIndex = 0
+	c.endCharIndex = 0
+	c.query = ""
+	a.document = &in
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	generation := a.generation
+	go runAsyncCompleter(ctx, a.completer, a.debounce, in, generation, a.results)
+}
+
+// Cancels the running completer and forgets its document.
+func (c *CompletionManager) cancelAsync() {
+	a := c.async
+	if a.cancel != nil {
+		a.cancel()
+		a.cancel = nil
+	}
+	a.generation++
+	a.document = nil
+	a.loading = false
+}
+
+// Waits for the debounce interval and calls the completer,
+// the messages about its progress are sent to the results channel.
+func runAsyncCompleter(ctx context.Context, completer AsyncCompleter, debounce time.Duration, d Document, generation uint64, results chan<- asyncCompletion) {
+	send := func(r asyncCompletion) bool {
+		select {
+		case results <- r:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	if debounce > 0 {
+		timer := time.NewTimer(debounce)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+	if !send(asyncCompletion{generation: generation, started: true}) {
+		return
+	}
+
+	suggestions, start, end := completer(ctx, d)
+	if ctx.Err() != nil {
+		return
+	}
+	send(asyncCompletion{
+		generation:     generation,
+		suggestions:    suggestions,
+		startCharIndex: start,
+		endCharIndex:   end,
+	})
+}
+
+// Returns the channel that receives the messages
+// of the asynchronous completer, nil when there's none.
+func (c *CompletionManager) asyncResults() <-chan asyncCompletion {
+	if c.async == nil {
+		return nil
+	}
+	return c.async.results
+}
+
+// Applies the message of the asynchronous completer.
+// Returns true when the completion box should be rendered again,
+// messages about outdated documents are discarded.
+func (c *CompletionManager) handleAsyncCompletion(r asyncCompletion) bool {
+	a := c.async
+	if a == nil || r.generation != a.generation || a.document == nil {
+		return false
+	}
+	if r.started {
+		a.loading = true
+		return true
+	}
+	a.loading = false
+	a.cancel = nil
+	c.tmp = groupSuggestions(r.suggestions)
+	c.startCharIndex = r.startCharIndex
+	c.endCharIndex = r.endCharIndex
+	c.updateQuery(*a.document)
+	c.selected = -1
+	c.verticalScroll = 0
+	return true
+}
@@ -0,0 +1,194 @@
+package prompt
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// Waits for the next message of the asynchronous completer and applies it.
+func receiveAsyncCompletion(t *testing.T, c *CompletionManager) asyncCompletion {
+	t.Helper()
+	select {
+	case r := <-c.asyncResults():
+		c.handleAsyncCompletion(r)
+		return r
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for the asynchronous completer")
+		return asyncCompletion{}
+	}
+}
+
+func TestAsyncCompleter(t *testing.T) {
+	release := make(chan struct{})
+	c := NewCompletionManager(6, CompletionManagerWithAsyncCompleter(
+		func(ctx context.Context, d Document) ([]Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+			<-release
+			return []Suggest{{Text: d.Text + "bar"}}, 0, istrings.RuneCountInString(d.Text)
+		},
+		0,
+	))
+
+	c.Update(Document{Text: "foo", cursorPosition: 3})
+	if r := receiveAsyncCompletion(t, c); !r.started {
+		t.Fatalf("Want the completer to be started, but got %#v", r)
+	}
+	if !c.Loading() {
+		t.Errorf("Want the completion to be loading")
+	}
+	if len(c.GetSuggestions()) != 0 {
+		t.Errorf("Want no suggestions while loading, but got %#v", c.GetSuggestions())
+	}
+
+	// the same document doesn't restart the completer
+	c.Update(Document{Text: "foo", cursorPosition: 3})
+	close(release)
+	receiveAsyncCompletion(t, c)
+	if c.Loading() {
+		t.Errorf("Want the completion to be loaded")
+	}
+	if want := []Suggest{{Text: "foobar"}}; !reflect.DeepEqual(want, c.GetSuggestions()) {
+		t.Errorf("Want %#v, but got %#v", want, c.GetSuggestions())
+	}
+	if c.startCharIndex != 0 || c.endCharIndex != 3 {
+		t.Errorf("Want the range 0-3, but got %d-%d", c.startCharIndex, c.endCharIndex)
+	}
+}
+
+func TestAsyncCompleterDiscardsStaleResults(t *testing.T) {
+	var mu sync.Mutex
+	var cancelled []string
+	c := NewCompletionManager(6, CompletionManagerWithAsyncCompleter(
+		func(ctx context.Context, d Document) ([]Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+			if d.Text == "slow" {
+				<-ctx.Done()
+				mu.Lock()
+				cancelled = append(cancelled, d.Text)
+				mu.Unlock()
+			}
+			return []Suggest{{Text: d.Text}}, 0, 0
+		},
+		0,
+	))
+
+	c.Update(Document{Text: "slow"})
+	receiveAsyncCompletion(t, c)
+	c.Update(Document{Text: "fast"})
+
+	// the message about the start of the stale completer is discarded
+	for {
+		r := receiveAsyncCompletion(t, c)
+		if !r.started {
+			break
+		}
+	}
+	if want := []Suggest{{Text: "fast"}}; !reflect.DeepEqual(want, c.GetSuggestions()) {
+		t.Errorf("Want %#v, but got %#v", want, c.GetSuggestions())
+	}
+
+	// a late result of an outdated document is ignored
+	if c.handleAsyncCompletion(asyncCompletion{generation: 0, suggestions: []Suggest{{Text: "slow"}}}) {
+		t.Errorf("Want the stale result to be discarded")
+	}
+	if want := []Suggest{{Text: "fast"}}; !reflect.DeepEqual(want, c.GetSuggestions()) {
+		t.Errorf("Want %#v, but got %#v", want, c.GetSuggestions())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(cancelled)
+		mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Want the context of the stale completer to be cancelled")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAsyncCompleterDebounce(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	c := NewCompletionManager(6, CompletionManagerWithAsyncCompleter(
+		func(ctx context.Context, d Document) ([]Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+			mu.Lock()
+			calls = append(calls, d.Text)
+			mu.Unlock()
+			return nil, 0, 0
+		},
+		50*time.Millisecond,
+	))
+
+	c.Update(Document{Text: "f", cursorPosition: 1})
+	c.Update(Document{Text: "fo", cursorPosition: 2})
+	c.Update(Document{Text: "foo", cursorPosition: 3})
+	receiveAsyncCompletion(t, c)
+	receiveAsyncCompletion(t, c)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"foo"}; !reflect.DeepEqual(want, calls) {
+		t.Errorf("Want %#v, but got %#v", want, calls)
+	}
+}
+
+func TestAsyncCompleterReset(t *testing.T) {
+	c := NewCompletionManager(6, CompletionManagerWithAsyncCompleter(
+		func(ctx context.Context, d Document) ([]Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+			return []Suggest{{Text: "foo"}}, 0, 0
+		},
+		time.Hour,
+	))
+	c.Update(Document{Text: "f"})
+	c.Reset()
+	if c.Loading() || len(c.GetSuggestions()) != 0 {
+		t.Errorf("Want the completion to be reset")
+	}
+	select {
+	case r := <-c.asyncResults():
+		t.Errorf("Want no messages after the reset, but got %#v", r)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestAsyncCompleterInput(t *testing.T) {
+	var mu sync.Mutex
+	var calls []string
+	p := newTestPrompt(
+		WithReader(&testReader{
+			inputs: [][]byte{[]byte("f"), {'\t'}, {'\t'}, {'\r'}, {'\r'}},
+			delay:  100 * time.Millisecond,
+		}),
+		WithAsyncCompleter(func(ctx context.Context, d Document) ([]Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+			mu.Lock()
+			calls = append(calls, d.Text)
+			mu.Unlock()
+			return []Suggest{{Text: "foo"}, {Text: "fob"}}, 0, istrings.RuneCountInString(d.Text)
+		}, 0),
+	)
+
+	result := make(chan string)
+	go func() { result <- p.Input() }()
+	select {
+	case got := <-result:
+		// cycling through the suggestions doesn't restart the completer
+		if got != "fob" {
+			t.Errorf("Want %q, but got %q", "fob", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for the input")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	// only typing and accepting the suggestion restart it
+	if want := []string{"f", "fob"}; !reflect.DeepEqual(want, calls) {
+		t.Errorf("Want the completer to be called for %#v, but got %#v", want, calls)
+	}
+}
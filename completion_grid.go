@@ -0,0 +1,123 @@
+package prompt
+
+import (
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// CompletionLayout determines how the suggestions
+// are arranged in the completion box.
+type CompletionLayout uint8
+
+const (
+	// CompletionLayoutList displays the suggestions in a single column
+	// together with their descriptions.
+	CompletionLayoutList CompletionLayout = iota
+	// CompletionLayoutGrid packs the suggestions into as many columns
+	// as fit in the terminal, like the menu completion of zsh.
+	// Descriptions and headers of groups are not displayed.
+	CompletionLayoutGrid
+)
+
+// CompletionManagerWithLayout sets the layout of the completion box.
+func CompletionManagerWithLayout(layout CompletionLayout) CompletionManagerOption {
+	return func(c *CompletionManager) {
+		c.layout = layout
+	}
+}
+
+// Layout returns the layout of the completion box.
+func (c *CompletionManager) Layout() CompletionLayout {
+	return c.layout
+}
+
+// NextRow selects the suggestion below the selected one
+// in the grid layout, wrapping around to the first row.
+// The first suggestion gets selected when nothing is selected.
+func (c *CompletionManager) NextRow() {
+	if len(c.tmp) == 0 {
+		return
+	}
+	columns := c.gridColumns()
+	switch {
+	case c.selected < 0:
+		c.selected = 0
+	case c.selected+columns < len(c.tmp):
+		c.selected += columns
+	default:
+		c.selected %= columns
+	}
+}
+
+// PreviousRow selects the suggestion above the selected one
+// in the grid layout, wrapping around to the last row.
+// The last suggestion gets selected when nothing is selected.
+func (c *CompletionManager) PreviousRow() {
+	if len(c.tmp) == 0 {
+		return
+	}
+	columns := c.gridColumns()
+	switch {
+	case c.selected < 0:
+		c.selected = len(c.tmp) - 1
+	case c.selected-columns >= 0:
+		c.selected -= columns
+	default:
+		rows := (len(c.tmp) + columns - 1) / columns
+		selected := (rows-1)*columns + c.selected
+		if selected >= len(c.tmp) {
+			selected -= columns
+		}
+		c.selected = selected
+	}
+}
+
+// Returns the number of columns of the grid, at least 1.
+func (c *CompletionManager) gridColumns() int {
+	if c.columns < 1 {
+		return 1
+	}
+	return c.columns
+}
+
+// Formats the displayed texts of the suggestions into cells of equal width
+// and returns them with the number of cells that fit in a row
+// of the given width.
+func formatGrid(suggests []Suggest, max istrings.Width, icons map[SuggestKind]string) (cells []string, columns int) {
+	iconCells := suggestionIconCells(suggests, icons)
+	texts := make([]string, len(suggests))
+	for i := range suggests {
+		texts[i] = suggests[i].displayText()
+		if iconCells != nil {
+			texts[i] = iconCells[i] + texts[i]
+		}
+	}
+	cells, width := formatTexts(texts, max, leftPrefix, leftSuffix)
+	if width == 0 {
+		return nil, 0
+	}
+	columns = int(max / width)
+	if columns < 1 {
+		columns = 1
+	}
+	return cells, columns
+}
+
+// Handles the keys that move the selection in the grid layout.
+// Returns true when the key has been handled.
+func (p *Prompt) handleCompletionGridKey(key Key) bool {
+	switch key {
+	case Down:
+		p.updateSuggestions(p.completion.NextRow)
+	case Up:
+		p.updateSuggestions(p.completion.PreviousRow)
+	case Right:
+		p.updateSuggestions(func() {
+			p.completion.Next()
+		})
+	case Left:
+		p.updateSuggestions(p.completion.Previous)
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package prompt
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+func TestCompletionManagerGridNavigation(t *testing.T) {
+	c := NewCompletionManager(3, CompletionManagerWithLayout(CompletionLayoutGrid))
+	// 3 columns, the last row has 1 suggestion
+	//   0 1 2
+	//   3 4 5
+	//   6
+	c.tmp = make([]Suggest, 7)
+	c.columns = 3
+
+	steps := []struct {
+		move     func()
+		selected int
+	}{
+		{c.NextRow, 0},
+		{c.NextRow, 3},
+		{c.NextRow, 6},
+		{c.NextRow, 0},
+		{func() { c.Next() }, 1},
+		{c.PreviousRow, 4},
+		{c.PreviousRow, 1},
+		{c.NextRow, 4},
+		{c.NextRow, 1},
+		{c.Previous, 0},
+		{c.PreviousRow, 6},
+	}
+	for i, s := range steps {
+		s.move()
+		if c.selected != s.selected {
+			t.Fatalf("step %d: want selected %d, but got %d", i, s.selected, c.selected)
+		}
+	}
+}
+
+func TestFormatGrid(t *testing.T) {
+	suggestions := []Suggest{{Text: "foo"}, {Text: "foobar"}, {Text: "baz"}}
+	cells, columns := formatGrid(suggestions, 20, nil)
+	if columns != 2 {
+		t.Errorf("Want 2 columns, but got %d", columns)
+	}
+	want := []string{" foo    ", " foobar ", " baz    "}
+	for i := range want {
+		if cells[i] != want[i] {
+			t.Errorf("Want cell %q, but got %q", want[i], cells[i])
+		}
+	}
+
+	if _, columns := formatGrid(suggestions, 7, nil); columns != 1 {
+		t.Errorf("Want 1 column, but got %d", columns)
+	}
+}
+
+func TestPromptCompletionGrid(t *testing.T) {
+	var suggestions []Suggest
+	for i := 0; i < 10; i++ {
+		suggestions = append(suggestions, Suggest{Text: fmt.Sprintf("suggestion_%07d", i)})
+	}
+	p := newTestPrompt(
+		WithCompletionLayout(CompletionLayoutGrid),
+		WithMaxSuggestion(2),
+		WithCompleter(func(d Document) ([]Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+			word := d.GetWordBeforeCursor()
+			end := d.CurrentRuneIndex()
+			return FilterHasPrefix(suggestions, word, false), end - istrings.RuneCountInString(word), end
+		}),
+	)
+	feedAll(p, "s")
+	p.completion.Update(*p.buffer.Document())
+
+	// cells are 20 columns wide, 3 of them fit in 79 columns
+	feedAll(p, "\t", "\x1b[B", "\x1b[B", "\x1b[C")
+	if got := p.buffer.Text(); got != "suggestion_0000007" {
+		t.Errorf("Want %q, but got %q", "suggestion_0000007", got)
+	}
+
+	// the page of the selected suggestion gets rendered
+	w := &testWriter{}
+	p.renderer.out = w
+	p.renderer.renderCompletion(p.buffer, p.completion)
+	if out := string(w.buffer); !strings.Contains(out, "_0000009") || strings.Contains(out, "_0000001") {
+		t.Errorf("Want the second page to be rendered, but got %q", out)
+	}
+
+	feedAll(p, "\x1b[A", "\x1b[D")
+	if got := p.buffer.Text(); got != "suggestion_0000003" {
+		t.Errorf("Want %q, but got %q", "suggestion_0000003", got)
+	}
+}
@@ -0,0 +1,91 @@
+package prompt
+
+// completionRow is a row of the completion box in the list layout,
+// either a header of a group or a suggestion.
+type completionRow struct {
+	group string // name of the group when the row is its header
+	index int    // index of the suggestion, -1 for a header
+}
+
+func (r completionRow) isHeader() bool {
+	return r.index == -1
+}
+
+// Returns the rows of the completion box in the list layout.
+// A header row precedes every run of suggestions with a non-empty group.
+func completionRows(suggestions []Suggest) []completionRow {
+	rows := make([]completionRow, 0, len(suggestions))
+	for i, s := range suggestions {
+		if s.Group != "" && (i == 0 || suggestions[i-1].Group != s.Group) {
+			rows = append(rows, completionRow{group: s.Group, index: -1})
+		}
+		rows = append(rows, completionRow{index: i})
+	}
+	return rows
+}
+
+// Reorders the suggestions so that the ones in the same group are next to each other.
+// Groups are ordered by their first suggestion, suggestions keep their order within a group.
+func groupSuggestions(suggestions []Suggest) []Suggest {
+	grouped := false
+	for _, s := range suggestions {
+		if s.Group != "" {
+			grouped = true
+			break
+		}
+	}
+	if !grouped {
+		return suggestions
+	}
+
+	var groups []string
+	byGroup := make(map[string][]Suggest)
+	for _, s := range suggestions {
+		if _, ok := byGroup[s.Group]; !ok {
+			groups = append(groups, s.Group)
+		}
+		byGroup[s.Group] = append(byGroup[s.Group], s)
+	}
+
+	result := make([]Suggest, 0, len(suggestions))
+	for _, g := range groups {
+		result = append(result, byGroup[g]...)
+	}
+	return result
+}
+
+// Scrolls the list layout so that the row of the selected suggestion is visible,
+// together with the header of its group when it is the first one in the group.
+func (c *CompletionManager) scrollToSelected() {
+	if c.selected < 0 {
+		c.verticalScroll = 0
+		return
+	}
+
+	rows := completionRows(c.tmp)
+	row := 0
+	for i, r := range rows {
+		if r.index == c.selected {
+			row = i
+			break
+		}
+	}
+	top := row
+	if top > 0 && rows[top-1].isHeader() {
+		top--
+	}
+
+	max := int(c.max)
+	if top < c.verticalScroll {
+		c.verticalScroll = top
+	}
+	if row >= c.verticalScroll+max {
+		c.verticalScroll = row - max + 1
+	}
+	if c.verticalScroll > len(rows)-max {
+		c.verticalScroll = len(rows) - max
+	}
+	if c.verticalScroll < 0 {
+		c.verticalScroll = 0
+	}
+}
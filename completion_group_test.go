@@ -0,0 +1,92 @@
+package prompt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+func TestGroupSuggestions(t *testing.T) {
+	in := []Suggest{
+		{Text: "ls", Group: "commands"},
+		{Text: "--all", Group: "flags"},
+		{Text: "cd", Group: "commands"},
+		{Text: "foo.txt"},
+	}
+	expected := []Suggest{
+		{Text: "ls", Group: "commands"},
+		{Text: "cd", Group: "commands"},
+		{Text: "--all", Group: "flags"},
+		{Text: "foo.txt"},
+	}
+	actual := groupSuggestions(in)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Should be %#v, but got %#v", expected, actual)
+	}
+
+	expectedRows := []completionRow{
+		{group: "commands", index: -1},
+		{index: 0},
+		{index: 1},
+		{group: "flags", index: -1},
+		{index: 2},
+		{index: 3},
+	}
+	if rows := completionRows(actual); !reflect.DeepEqual(rows, expectedRows) {
+		t.Errorf("Should be %#v, but got %#v", expectedRows, rows)
+	}
+}
+
+func TestCompletionManagerScrollsToGroupHeader(t *testing.T) {
+	c := NewCompletionManager(2)
+	c.tmp = []Suggest{
+		{Text: "ls", Group: "commands"},
+		{Text: "cd", Group: "commands"},
+		{Text: "--all", Group: "flags"},
+	}
+
+	tests := []struct {
+		move   func()
+		scroll int
+	}{
+		{func() { c.Next() }, 0},
+		{func() { c.Next() }, 1},
+		{func() { c.Next() }, 3},
+		{c.Previous, 2},
+		// the header of the first group gets visible again
+		{c.Previous, 0},
+		{c.Previous, 0},
+		{c.Previous, 3},
+	}
+	for i, tt := range tests {
+		tt.move()
+		if c.verticalScroll != tt.scroll {
+			t.Errorf("step %d: want scroll %d, but got %d", i, tt.scroll, c.verticalScroll)
+		}
+	}
+}
+
+func TestRenderCompletionGroupHeaders(t *testing.T) {
+	p := newTestPrompt(WithCompleter(func(d Document) ([]Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+		return []Suggest{
+			{Text: "--all", Group: "Flags"},
+			{Text: "ls", Group: "Commands"},
+		}, 0, 0
+	}))
+	p.completion.Update(*p.buffer.Document())
+	if got := p.completion.GetSuggestions()[0].Text; got != "--all" {
+		t.Errorf("Want %q, but got %q", "--all", got)
+	}
+
+	w := &testWriter{}
+	p.renderer.out = w
+	p.renderer.renderCompletion(p.buffer, p.completion)
+	out := string(w.buffer)
+	flags := strings.Index(out, " Flags ")
+	commands := strings.Index(out, " Commands ")
+	if flags == -1 || commands == -1 || flags > commands {
+		t.Errorf("Want the headers of both groups in order, but got %q", out)
+	}
+}
@@ -1,6 +1,7 @@
 package prompt
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -109,7 +110,7 @@ func TestFormatShortSuggestion(t *testing.T) {
 	}
 
 	for i, s := range scenarioTable {
-		actual, width := formatSuggestions(s.in, s.max)
+		actual, width := formatSuggestions(s.in, s.max, nil)
 		if width != s.exWidth {
 			t.Errorf("[scenario %d] Want %d but got %d\n", i, s.exWidth, width)
 		}
@@ -213,3 +214,200 @@ func TestNoopCompleter(t *testing.T) {
 		t.Errorf("NoopCompleter should return nil")
 	}
 }
+
+func TestCompletionManagerCommonPrefix(t *testing.T) {
+	tests := []struct {
+		suggestions []Suggest
+		want        string
+	}{
+		{nil, ""},
+		{[]Suggest{{Text: "foo"}}, "foo"},
+		{[]Suggest{{Text: "foobar"}, {Text: "foobaz"}, {Text: "foo"}}, "foo"},
+		{[]Suggest{{Text: "文字列"}, {Text: "文字"}}, "文字"},
+		{[]Suggest{{Text: "foo"}, {Text: "bar"}}, ""},
+	}
+	for _, tt := range tests {
+		c := NewCompletionManager(6)
+		c.tmp = tt.suggestions
+		if got := c.CommonPrefix(); got != tt.want {
+			t.Errorf("Want %q, but got %q", tt.want, got)
+		}
+	}
+}
+
+func TestPromptCommonPrefixCompletion(t *testing.T) {
+	p := newTestPrompt(
+		WithCompletionBehavior(CompletionBehaviorCommonPrefix),
+		WithCompleter(func(d Document) ([]Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+			word := d.GetWordBeforeCursor()
+			end := d.CurrentRuneIndex()
+			suggestions := []Suggest{{Text: "foobar"}, {Text: "foobaz"}}
+			return FilterHasPrefix(suggestions, word, true), end - istrings.RuneCountInString(word), end
+		}),
+	)
+	feedAll(p, "F")
+	p.completion.Update(*p.buffer.Document())
+
+	// the first Tab replaces the word with the common prefix
+	feedAll(p, "\t")
+	if got := p.buffer.Text(); got != "fooba" {
+		t.Errorf("Want %q, but got %q", "fooba", got)
+	}
+	if p.completion.Completing() {
+		t.Errorf("Want no suggestion to be selected")
+	}
+
+	// the next one selects the first suggestion
+	p.completion.Update(*p.buffer.Document())
+	feedAll(p, "\t")
+	if got := p.buffer.Text(); got != "foobar" {
+		t.Errorf("Want %q, but got %q", "foobar", got)
+	}
+}
+
+func TestFormatSuggestionsDisplayTextAndIcons(t *testing.T) {
+	in := []Suggest{
+		{Text: "foo", DisplayText: "foo()", Kind: SuggestKindCommand},
+		{Text: "--bar", Kind: SuggestKindFlag},
+		{Text: "baz"},
+	}
+	icons := map[SuggestKind]string{SuggestKindCommand: ">", SuggestKindFlag: "--"}
+	expected := []Suggest{
+		{Text: " >  foo() ", Description: ""},
+		{Text: " -- --bar ", Description: ""},
+		{Text: "    baz   ", Description: ""},
+	}
+	actual, width := formatSuggestions(in, 40, icons)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Should be %#v, but got %#v", expected, actual)
+	}
+	if width != 10 {
+		t.Errorf("Should be %#v, but got %#v", 10, width)
+	}
+}
+
+func TestPromptSuggestInsertTextAndCursorOffset(t *testing.T) {
+	p := newTestPrompt(WithCompleter(func(d Document) ([]Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+		word := d.GetWordBeforeCursor()
+		end := d.CurrentRuneIndex()
+		suggestions := []Suggest{
+			{Text: "len", InsertText: "len()", CursorOffset: -1},
+			{Text: "list", DisplayText: "list (builtin)"},
+		}
+		return FilterHasPrefix(suggestions, word, false), end - istrings.RuneCountInString(word), end
+	}))
+	feedAll(p, "l")
+	p.completion.Update(*p.buffer.Document())
+
+	feedAll(p, "\t")
+	if got := p.buffer.Text(); got != "len()" {
+		t.Errorf("Want %q, but got %q", "len()", got)
+	}
+	if got := p.buffer.cursorPosition; got != 4 {
+		t.Errorf("Want cursor 4, but got %d", got)
+	}
+
+	feedAll(p, "\t")
+	if got := p.buffer.Text(); got != "list" {
+		t.Errorf("Want %q, but got %q", "list", got)
+	}
+	if got := p.buffer.cursorPosition; got != 4 {
+		t.Errorf("Want cursor 4, but got %d", got)
+	}
+}
+
+func TestCompletionManagerPaging(t *testing.T) {
+	c := NewCompletionManager(3)
+	for i := 0; i < 8; i++ {
+		c.tmp = append(c.tmp, Suggest{Text: fmt.Sprintf("item%d", i)})
+	}
+
+	steps := []struct {
+		name string
+		fn   func()
+		want int
+	}{
+		{"next page from nothing selected", c.NextPage, 2},
+		{"next page", c.NextPage, 5},
+		{"next page stops at the last suggestion", c.NextPage, 7},
+		{"previous page", c.PreviousPage, 4},
+		{"first", c.First, 0},
+		{"previous page stops at the first suggestion", c.PreviousPage, 0},
+		{"last", c.Last, 7},
+	}
+	for _, s := range steps {
+		s.fn()
+		if c.selected != s.want {
+			t.Errorf("%s: want %d, but got %d", s.name, s.want, c.selected)
+		}
+	}
+	if c.verticalScroll != 5 {
+		t.Errorf("Want vertical scroll 5, but got %d", c.verticalScroll)
+	}
+}
+
+func TestPromptCompletionMenuKeys(t *testing.T) {
+	newPrompt := func() *Prompt {
+		p := newTestPrompt(
+			WithMaxSuggestion(2),
+			WithCompleter(func(d Document) ([]Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+				word := d.GetWordBeforeCursor()
+				end := d.CurrentRuneIndex()
+				suggestions := []Suggest{{Text: "foo"}, {Text: "fob"}, {Text: "fuzz"}, {Text: "fizz"}, {Text: "faz"}}
+				return FilterHasPrefix(suggestions, word, false), end - istrings.RuneCountInString(word), end
+			}),
+		)
+		feedAll(p, "git f")
+		p.completion.Update(*p.buffer.Document())
+		return p
+	}
+
+	t.Run("page and jump", func(t *testing.T) {
+		p := newPrompt()
+		feedAll(p, "\t", "\x1b[6~")
+		if got := p.buffer.Text(); got != "git fuzz" {
+			t.Errorf("Want %q, but got %q", "git fuzz", got)
+		}
+		feedAll(p, "\x1b[F")
+		if got := p.buffer.Text(); got != "git faz" {
+			t.Errorf("Want %q, but got %q", "git faz", got)
+		}
+		feedAll(p, "\x1b[5~")
+		if got := p.buffer.Text(); got != "git fuzz" {
+			t.Errorf("Want %q, but got %q", "git fuzz", got)
+		}
+		feedAll(p, "\x1b[H")
+		if got := p.buffer.Text(); got != "git foo" {
+			t.Errorf("Want %q, but got %q", "git foo", got)
+		}
+	})
+
+	for name, key := range map[string]string{"escape": "\x1b", "ctrl+g": "\x07"} {
+		t.Run(name+" restores the original word", func(t *testing.T) {
+			p := newPrompt()
+			feedAll(p, "\t", "\t", key)
+			if got := p.buffer.Text(); got != "git f" {
+				t.Errorf("Want %q, but got %q", "git f", got)
+			}
+			if p.completion.Completing() {
+				t.Errorf("Want the completion to be cancelled")
+			}
+		})
+	}
+
+	t.Run("enter accepts the selection", func(t *testing.T) {
+		p := newPrompt()
+		if input := feedAll(p, "\t", "\n"); input != nil {
+			t.Errorf("Want the input not to be executed, but got %#v", input)
+		}
+		if got := p.buffer.Text(); got != "git foo" {
+			t.Errorf("Want %q, but got %q", "git foo", got)
+		}
+		if p.completion.Completing() {
+			t.Errorf("Want the completion to be finished")
+		}
+		if input := feedAll(p, "\n"); input == nil || input.input != "git foo" {
+			t.Errorf("Want user input %q, but got %#v", "git foo", input)
+		}
+	})
+}
@@ -1,5 +1,10 @@
 package prompt
 
+import (
+	"regexp"
+	"time"
+)
+
 // Option is the type to replace default parameters.
 // prompt.New accepts any number of options (this is functional option pattern).
 type Option func(prompt *Prompt) error
@@ -44,6 +49,25 @@ func WithCompleter(c Completer) Option {
 	}
 }
 
+// WithAsyncCompleter is an option that sets a custom AsyncCompleter object
+// used in place of the synchronous Completer.
+// It gets called after the input hasn't changed for the debounce interval.
+func WithAsyncCompleter(c AsyncCompleter, debounce time.Duration) Option {
+	return func(p *Prompt) error {
+		CompletionManagerWithAsyncCompleter(c, debounce)(p.completion)
+		return nil
+	}
+}
+
+// WithAsyncCompletionLoadingText is an option that sets the text displayed
+// in the completion box while the AsyncCompleter is running.
+func WithAsyncCompletionLoadingText(text string) Option {
+	return func(p *Prompt) error {
+		CompletionManagerWithAsyncLoadingText(text)(p.completion)
+		return nil
+	}
+}
+
 // WithReader can be used to set a custom Reader object.
 func WithReader(r Reader) Option {
 	return func(p *Prompt) error {
@@ -101,6 +125,17 @@ func WithPrefixCallback(f PrefixCallback) Option {
 	}
 }
 
+// WithViModePrefixCallback can be used to change the prefix dynamically
+// depending on the current mode of the vi key bindings.
+func WithViModePrefixCallback(f ViModePrefixCallback) Option {
+	return func(p *Prompt) error {
+		p.renderer.prefixCallback = func() string {
+			return f(p.ViMode())
+		}
+		return nil
+	}
+}
+
 // WithPrefixTextColor change a text color of prefix string
 func WithPrefixTextColor(x Color) Option {
 	return func(p *Prompt) error {
@@ -157,6 +192,48 @@ func WithSelectedSuggestionTextColor(x Color) Option {
 	}
 }
 
+// WithSuggestionMatchTextColor to change a text color of the characters matching the input inside suggestions drop down box.
+func WithSuggestionMatchTextColor(x Color) Option {
+	return func(p *Prompt) error {
+		p.renderer.suggestionMatchTextColor = x
+		return nil
+	}
+}
+
+// WithSelectedSuggestionMatchTextColor to change a text color of the characters matching the input
+// in the suggestion which is selected inside suggestions drop down box.
+func WithSelectedSuggestionMatchTextColor(x Color) Option {
+	return func(p *Prompt) error {
+		p.renderer.selectedSuggestionMatchTextColor = x
+		return nil
+	}
+}
+
+// WithGroupHeaderTextColor to change a text color of the headers of groups inside suggestions drop down box.
+func WithGroupHeaderTextColor(x Color) Option {
+	return func(p *Prompt) error {
+		p.renderer.groupHeaderTextColor = x
+		return nil
+	}
+}
+
+// WithGroupHeaderBGColor to change a background color of the headers of groups inside suggestions drop down box.
+func WithGroupHeaderBGColor(x Color) Option {
+	return func(p *Prompt) error {
+		p.renderer.groupHeaderBGColor = x
+		return nil
+	}
+}
+
+// WithSuggestKindIcon to change the icon displayed next to the suggestions of the given kind
+// inside suggestions drop down box. An empty icon hides it.
+func WithSuggestKindIcon(kind SuggestKind, icon string) Option {
+	return func(p *Prompt) error {
+		p.renderer.suggestKindIcons[kind] = icon
+		return nil
+	}
+}
+
 // WithSelectedSuggestionBGColor to change a background color for completed text which is selected inside suggestions drop down box.
 func WithSelectedSuggestionBGColor(x Color) Option {
 	return func(p *Prompt) error {
@@ -213,7 +290,48 @@ func WithScrollbarBGColor(x Color) Option {
 	}
 }
 
+// WithHistorySearchMatchTextColor to change a text color of the text matched by the history search.
+func WithHistorySearchMatchTextColor(x Color) Option {
+	return func(p *Prompt) error {
+		p.renderer.historySearchMatchTextColor = x
+		return nil
+	}
+}
+
+// WithHistorySearchMatchBGColor to change a background color of the text matched by the history search.
+func WithHistorySearchMatchBGColor(x Color) Option {
+	return func(p *Prompt) error {
+		p.renderer.historySearchMatchBGColor = x
+		return nil
+	}
+}
+
+// WithAutoSuggestionTextColor to change a text color of the suggestion displayed after the input.
+func WithAutoSuggestionTextColor(x Color) Option {
+	return func(p *Prompt) error {
+		p.renderer.autoSuggestionTextColor = x
+		return nil
+	}
+}
+
+// WithCompletionLayout sets the layout of the completion box.
+func WithCompletionLayout(layout CompletionLayout) Option {
+	return func(p *Prompt) error {
+		CompletionManagerWithLayout(layout)(p.completion)
+		return nil
+	}
+}
+
+// WithCompletionBehavior sets what Tab does when no suggestion is selected.
+func WithCompletionBehavior(behavior CompletionBehavior) Option {
+	return func(p *Prompt) error {
+		CompletionManagerWithBehavior(behavior)(p.completion)
+		return nil
+	}
+}
+
 // WithMaxSuggestion specify the max number of displayed suggestions.
+// In the grid layout it is the max number of displayed rows.
 func WithMaxSuggestion(x uint16) Option {
 	return func(p *Prompt) error {
 		p.completion.max = x
@@ -230,6 +348,122 @@ func WithHistory(x []string) Option {
 	}
 }
 
+// WithHistoryStore loads the history from the store
+// and adds every new entry to it.
+func WithHistoryStore(store HistoryStore) Option {
+	return func(p *Prompt) error {
+		err := store.Iterate(func(entry HistoryEntry) bool {
+			p.history.histories = append(p.history.histories, entry.Text)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		p.history.store = store
+		p.history.Clear()
+		return nil
+	}
+}
+
+// WithHistoryFile loads the history from the file at the given path
+// and appends every new entry to it (see HistoryFile).
+func WithHistoryFile(path string, opts ...HistoryFileOption) Option {
+	return WithHistoryStore(NewHistoryFile(path, opts...))
+}
+
+// WithHistoryControl sets the policies that control
+// which inputs get added to the history.
+func WithHistoryControl(c HistoryControl) Option {
+	return func(p *Prompt) error {
+		p.history.control = c
+		return nil
+	}
+}
+
+// WithHistoryIgnore makes the history skip inputs
+// that match any of the given regular expressions.
+func WithHistoryIgnore(patterns ...string) Option {
+	return func(p *Prompt) error {
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return err
+			}
+			p.history.ignore = append(p.history.ignore, re)
+		}
+		return nil
+	}
+}
+
+// WithHistoryMaxSize sets the maximum number of entries
+// kept in the history, the oldest entries are removed first.
+// Zero means no limit.
+func WithHistoryMaxSize(size int) Option {
+	return func(p *Prompt) error {
+		p.history.maxSize = size
+		return nil
+	}
+}
+
+// WithHistoryPrefixSearch makes Up and Down walk only through
+// the history entries that start with the text before the cursor.
+// The cursor stays where it was and duplicate entries are skipped.
+func WithHistoryPrefixSearch() Option {
+	return func(p *Prompt) error {
+		p.history.prefixSearch = true
+		return nil
+	}
+}
+
+// WithHistoryExpansion enables bash-style history expansion
+// (like !!, !$ or ^old^new) of the input when Enter is pressed (see ExpandHistory).
+// When the expansion fails the error gets printed and the input isn't executed.
+func WithHistoryExpansion() Option {
+	return func(p *Prompt) error {
+		p.historyExpansion = true
+		return nil
+	}
+}
+
+// WithAutoSuggester sets a function that suggests the text
+// displayed after the input, like fish shell does.
+// Right, End or Ctrl+F at the end of the input accept the whole suggestion,
+// Alt+F or Alt+Right accept its next word.
+func WithAutoSuggester(fn AutoSuggester) Option {
+	return func(p *Prompt) error {
+		p.autoSuggester = fn
+		return nil
+	}
+}
+
+// WithHistoryAutoSuggestion makes the prompt suggest the rest of
+// the newest history entry that starts with the input (see WithAutoSuggester).
+func WithHistoryAutoSuggestion() Option {
+	return func(p *Prompt) error {
+		p.autoSuggester = p.history.AutoSuggest
+		return nil
+	}
+}
+
+// WithStatusExecutor sets an executor that reports the exit status
+// of the input which gets stored in the history
+// when its store is a HistoryExitStatusStore.
+// It's called in place of the executor passed to New.
+func WithStatusExecutor(fn StatusExecutor) Option {
+	return func(p *Prompt) error {
+		p.statusExecutor = fn
+		return nil
+	}
+}
+
+// WithKillRingSize sets the maximum number of entries stored in the kill ring.
+func WithKillRingSize(size int) Option {
+	return func(p *Prompt) error {
+		p.killRing.max = size
+		return nil
+	}
+}
+
 // WithKeyBindMode set a key bind mode.
 func WithKeyBindMode(m KeyBindMode) Option {
 	return func(p *Prompt) error {
@@ -254,6 +488,33 @@ func WithKeyBind(b ...KeyBind) Option {
 	}
 }
 
+// WithKeySequenceBind to set a custom key sequence bind.
+// Key sequences override the key binds of their first key.
+func WithKeySequenceBind(b ...KeySequenceBind) Option {
+	return func(p *Prompt) error {
+		p.keySequenceBindings = append(p.keySequenceBindings, b...)
+		return nil
+	}
+}
+
+// WithKeySequenceTimeout to set how long the prompt waits for the next key
+// of an incomplete key sequence. Zero means to wait forever.
+func WithKeySequenceTimeout(t time.Duration) Option {
+	return func(p *Prompt) error {
+		p.keySequenceTimeout = t
+		return nil
+	}
+}
+
+// WithExecuteOnEditorSave makes the prompt execute the input
+// right after it has been edited in the external editor (see OpenInEditor).
+func WithExecuteOnEditorSave() Option {
+	return func(p *Prompt) error {
+		p.executeOnEditorSave = true
+		return nil
+	}
+}
+
 // WithASCIICodeBind to set a custom key bind.
 func WithASCIICodeBind(b ...ASCIICodeBind) Option {
 	return func(p *Prompt) error {
@@ -301,8 +562,11 @@ func New(executor Executor, opts ...Option) *Prompt {
 		renderer:               NewRenderer(),
 		buffer:                 NewBuffer(),
 		executor:               executor,
+		sessionID:              newSessionID(),
 		history:                NewHistory(),
 		completion:             NewCompletionManager(6),
+		killRing:               NewKillRing(DefaultKillRingSize),
+		keySequenceTimeout:     DefaultKeySequenceTimeout,
 		executeOnEnterCallback: DefaultExecuteOnEnterCallback,
 		keyBindMode:            EmacsKeyBind, // All the above assume that bash is running in the default Emacs setting
 	}
@@ -312,5 +576,6 @@ func New(executor Executor, opts ...Option) *Prompt {
 			panic(err)
 		}
 	}
+	pt.history.compact()
 	return pt
 }
@@ -29,6 +29,15 @@ func NewDocument() *Document {
 	}
 }
 
+// NewDocumentWithText returns a new document with the given text
+// and the cursor at its end.
+func NewDocumentWithText(text string) *Document {
+	return &Document{
+		Text:           text,
+		cursorPosition: istrings.RuneCountInString(text),
+	}
+}
+
 // LastKeyStroke return the last key pressed in this document.
 func (d *Document) LastKeyStroke() Key {
 	return d.lastKey
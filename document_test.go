@@ -1351,3 +1351,13 @@ func TestDocument_GetEndOfLinePosition(t *testing.T) {
 		t.Errorf("Should be %#v, got %#v", ex, ac)
 	}
 }
+
+func TestNewDocumentWithText(t *testing.T) {
+	d := NewDocumentWithText("こんにちは")
+	if got := d.CurrentRuneIndex(); got != 5 {
+		t.Errorf("Should be %#v, got %#v", 5, got)
+	}
+	if got := d.TextBeforeCursor(); got != "こんにちは" {
+		t.Errorf("Should be %#v, got %#v", "こんにちは", got)
+	}
+}
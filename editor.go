@@ -0,0 +1,90 @@
+package prompt
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/plandex-ai/go-prompt/debug"
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// OpenInEditor Edit the buffer in the editor specified by $VISUAL or $EDITOR
+func OpenInEditor(p *Prompt) bool {
+	p.editorRequested = true
+	return false
+}
+
+// Returns the command of the editor that should be used
+// to edit the buffer.
+func editorCommand() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(env)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// Opens the buffer in the editor and returns the user input
+// when it should be executed right away.
+// The reader should be closed before this gets called.
+func (p *Prompt) runEditor() *UserInput {
+	p.editorRequested = false
+	if err := p.editBuffer(); err != nil {
+		debug.Log(err.Error())
+		return nil
+	}
+	if !p.executeOnEditorSave {
+		return nil
+	}
+	return p.acceptInput()
+}
+
+// Writes the text of the buffer to a temporary file,
+// opens it in the editor and loads the saved text back into the buffer.
+func (p *Prompt) editBuffer() error {
+	f, err := os.CreateTemp("", "prompt-*.txt")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.WriteString(p.buffer.Text()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	args := append(editorCommand(), f.Name())
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+
+	content, err := os.ReadFile(f.Name())
+	if err != nil {
+		return err
+	}
+	// editors usually end the file with a newline
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+
+	p.buffer.beginEdit(editOther)
+	p.buffer.setDocument(
+		&Document{Text: text, cursorPosition: istrings.RuneCountInString(text)},
+		p.renderer.UserInputColumns(),
+		p.renderer.row,
+	)
+	p.buffer.endEdit()
+	return nil
+}
@@ -0,0 +1,65 @@
+package prompt
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestOpenInEditor(t *testing.T) {
+	if _, err := exec.LookPath("sed"); err != nil {
+		t.Skip("sed is not available")
+	}
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("VISUAL", "sed -i.bak s/foo/bar/")
+
+	p := newTestPrompt(WithExecuteOnEditorSave())
+	feedAll(p, "echo foo", string([]byte{0x18}), string([]byte{0x5}))
+	if !p.editorRequested {
+		t.Fatalf("Want the editor to be requested")
+	}
+
+	input := p.runEditor()
+	if p.editorRequested {
+		t.Errorf("Want the editor request to be reset")
+	}
+	if input == nil || input.input != "echo bar" {
+		t.Errorf("Want user input %q, but got %#v", "echo bar", input)
+	}
+	if got := p.buffer.Text(); got != "" {
+		t.Errorf("Want an empty buffer, but got %q", got)
+	}
+}
+
+func TestEditBuffer(t *testing.T) {
+	if _, err := exec.LookPath("sed"); err != nil {
+		t.Skip("sed is not available")
+	}
+	t.Setenv("TMPDIR", t.TempDir())
+	t.Setenv("VISUAL", "sed -i.bak s/foo/bar/")
+
+	p := newTestPrompt()
+	feedAll(p, "foo\nbaz")
+	if err := p.editBuffer(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if got := p.buffer.Text(); got != "bar\nbaz" {
+		t.Errorf("Want %q, but got %q", "bar\nbaz", got)
+	}
+	if got := p.buffer.cursorPosition; got != 7 {
+		t.Errorf("Want cursor 7, but got %d", got)
+	}
+
+	// loading the edited text can be undone
+	p.Undo()
+	if got := p.buffer.Text(); got != "foo\nbaz" {
+		t.Errorf("Want %q, but got %q", "foo\nbaz", got)
+	}
+
+	t.Setenv("VISUAL", "false")
+	if err := p.editBuffer(); err == nil {
+		t.Errorf("Want an error when the editor fails")
+	}
+	if got := p.buffer.Text(); got != "foo\nbaz" {
+		t.Errorf("Want %q, but got %q", "foo\nbaz", got)
+	}
+}
@@ -21,6 +21,10 @@ Moving the cursor
 * [x] Ctrl + f   Forward one character
 * [x] Ctrl + b   Backward one character
 * [x] Ctrl + xx  Toggle between the start of line and current cursor position
+* [x] Ctrl + r   Search the history backwards
+* [x] Ctrl + s   Search the history forwards
+
+* [x] Ctrl + x Ctrl + e   Edit the input in $VISUAL or $EDITOR
 
 Editing
 -------
@@ -29,15 +33,17 @@ Editing
 * [x] Ctrl + d   Delete character under the cursor
 * [x] Ctrl + h   Delete character before the cursor (Backspace)
 
-* [x] Ctrl + w   Cut the Word before the cursor to the clipboard.
-* [x] Ctrl + k   Cut the Line after the cursor to the clipboard.
-* [x] Ctrl + u   Cut/delete the Line before the cursor to the clipboard.
+* [x] Ctrl + w   Cut the Word before the cursor to the kill ring.
+* [x] Ctrl + k   Cut the Line after the cursor to the kill ring.
+* [x] Ctrl + u   Cut/delete the Line before the cursor to the kill ring.
 
 * [ ] Ctrl + t   Swap the last two characters before the cursor (typo).
 * [ ] Esc  + t   Swap the last two words before the cursor.
 
-* [ ] ctrl + y   Paste the last thing to be cut (yank)
-* [ ] ctrl + _   Undo
+* [x] ctrl + y   Paste the last thing to be cut (yank)
+* [x] Alt  + y   Replace the pasted text with an older thing to be cut (yank-pop)
+* [x] ctrl + _   Undo
+* [x] Ctrl + x Ctrl + u   Undo
 
 */
 
@@ -63,26 +69,12 @@ var emacsKeyBindings = []KeyBind{
 	// Cut the Line after the cursor
 	{
 		Key: ControlK,
-		Fn: func(p *Prompt) bool {
-			p.buffer.DeleteRunes(
-				istrings.RuneCountInString(p.buffer.Document().CurrentLineAfterCursor()),
-				p.renderer.col,
-				p.renderer.row,
-			)
-			return true
-		},
+		Fn:  KillLine,
 	},
 	// Cut/delete the Line before the cursor
 	{
 		Key: ControlU,
-		Fn: func(p *Prompt) bool {
-			p.buffer.DeleteBeforeCursorRunes(
-				istrings.RuneCountInString(p.buffer.Document().CurrentLineBeforeCursor()),
-				p.renderer.col,
-				p.renderer.row,
-			)
-			return true
-		},
+		Fn:  KillLineBeforeCursor,
 	},
 	// Delete character under the cursor
 	{
@@ -138,11 +130,38 @@ var emacsKeyBindings = []KeyBind{
 	// Cut the Word before the cursor.
 	{
 		Key: ControlW,
-		Fn:  DeleteWordBeforeCursor,
+		Fn:  KillWordBeforeCursor,
 	},
 	{
 		Key: AltBackspace,
-		Fn:  DeleteWordBeforeCursor,
+		Fn:  KillWordBeforeCursor,
+	},
+	// Paste the last thing to be cut
+	{
+		Key: ControlY,
+		Fn:  Yank,
+	},
+	// Replace the pasted text with an older thing to be cut
+	{
+		Key: AltY,
+		Fn:  YankPop,
+	},
+	// Undo
+	{
+		Key: ControlUnderscore,
+		Fn: func(p *Prompt) bool {
+			return p.Undo()
+		},
+	},
+	// Search the history backwards
+	{
+		Key: ControlR,
+		Fn:  ReverseSearchHistory,
+	},
+	// Search the history forwards
+	{
+		Key: ControlS,
+		Fn:  ForwardSearchHistory,
 	},
 	// Clear the Screen, similar to the clear command
 	{
@@ -155,3 +174,23 @@ var emacsKeyBindings = []KeyBind{
 		},
 	},
 }
+
+var emacsKeySequenceBindings = []KeySequenceBind{
+	// Toggle between the start of line and current cursor position
+	{
+		Keys: []Key{ControlX, ControlX},
+		Fn:   ToggleLineStart,
+	},
+	// Edit the input in the external editor
+	{
+		Keys: []Key{ControlX, ControlE},
+		Fn:   OpenInEditor,
+	},
+	// Undo
+	{
+		Keys: []Key{ControlX, ControlU},
+		Fn: func(p *Prompt) bool {
+			return p.Undo()
+		},
+	},
+}
@@ -1,6 +1,9 @@
 package prompt
 
-import "strings"
+import (
+	"sort"
+	"strings"
+)
 
 // Filter is the type to filter the prompt.Suggestion array.
 type Filter func([]Suggest, string, bool) []Suggest
@@ -26,29 +29,38 @@ func FilterContains(completions []Suggest, sub string, ignoreCase bool) []Sugges
 //
 //	"Good food is gone"
 //	    ^  ^      ^
+//
+// The suggestions are sorted by the score of the match (see FuzzyMatch),
+// suggestions with equal scores keep their order.
 func FilterFuzzy(completions []Suggest, sub string, ignoreCase bool) []Suggest {
-	return filterSuggestions(completions, sub, ignoreCase, fuzzyMatch)
-}
-
-func fuzzyMatch(s, sub string) bool {
-	sChars := []rune(s)
-	sIdx := 0
+	if sub == "" {
+		return completions
+	}
 
-	// https://staticcheck.io/docs/checks#S1029
-	for _, c := range sub {
-		found := false
-		for ; sIdx < len(sChars); sIdx++ {
-			if sChars[sIdx] == c {
-				found = true
-				sIdx++
-				break
-			}
-		}
-		if !found {
-			return false
+	type scoredSuggestion struct {
+		suggestion Suggest
+		score      int
+	}
+	scored := make([]scoredSuggestion, 0, len(completions))
+	for _, c := range completions {
+		if score, _, ok := FuzzyMatch(c.Text, sub, ignoreCase); ok {
+			scored = append(scored, scoredSuggestion{suggestion: c, score: score})
 		}
 	}
-	return true
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	ret := make([]Suggest, len(scored))
+	for i, s := range scored {
+		ret[i] = s.suggestion
+	}
+	return ret
+}
+
+func fuzzyMatch(s, sub string) bool {
+	_, _, ok := FuzzyMatch(s, sub, false)
+	return ok
 }
 
 func filterSuggestions(suggestions []Suggest, sub string, ignoreCase bool, function func(string, string) bool) []Suggest {
@@ -3,6 +3,8 @@ package prompt
 import (
 	"reflect"
 	"testing"
+
+	istrings "github.com/plandex-ai/go-prompt/strings"
 )
 
 func TestFilter(t *testing.T) {
@@ -162,3 +164,70 @@ func TestFuzzyMatch(t *testing.T) {
 		}
 	}
 }
+
+func TestFilterFuzzySortsByScore(t *testing.T) {
+	list := []Suggest{
+		{Text: "fgetbuf"},
+		{Text: "get_buffer"},
+		{Text: "foo"},
+		{Text: "GetBuffer"},
+		{Text: "getbuffer"},
+	}
+	expected := []Suggest{
+		{Text: "get_buffer"},
+		{Text: "GetBuffer"},
+		{Text: "getbuffer"},
+		{Text: "fgetbuf"},
+	}
+	if actual := FilterFuzzy(list, "gb", true); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Should be %#v, but got %#v", expected, actual)
+	}
+}
+
+func TestFuzzyMatchPositions(t *testing.T) {
+	tests := []struct {
+		text      string
+		pattern   string
+		positions []istrings.RuneNumber
+	}{
+		{"dog house", "dh", []istrings.RuneNumber{0, 4}},
+		{"axxbxx_ab", "ab", []istrings.RuneNumber{7, 8}},
+		{"fooBarBaz", "bb", []istrings.RuneNumber{3, 6}},
+		{"xab ab", "ab", []istrings.RuneNumber{4, 5}},
+		{"文字 with 今日", "w今", []istrings.RuneNumber{3, 8}},
+	}
+
+	for _, test := range tests {
+		_, positions, ok := FuzzyMatch(test.text, test.pattern, true)
+		if !ok {
+			t.Errorf("%q in %q: expected a match", test.pattern, test.text)
+			continue
+		}
+		if !reflect.DeepEqual(positions, test.positions) {
+			t.Errorf("%q in %q: expected positions %v, got %v", test.pattern, test.text, test.positions, positions)
+		}
+	}
+}
+
+func TestFuzzyMatchScore(t *testing.T) {
+	score := func(text, pattern string) int {
+		s, _, ok := FuzzyMatch(text, pattern, false)
+		if !ok {
+			t.Fatalf("%q in %q: expected a match", pattern, text)
+		}
+		return s
+	}
+
+	if a, b := score("foobar", "fb"), score("foo_bar", "fb"); a >= b {
+		t.Errorf("Expected a word boundary match to score higher: %d >= %d", a, b)
+	}
+	if a, b := score("foobar", "fb"), score("fooBar", "fB"); a >= b {
+		t.Errorf("Expected a camel case match to score higher: %d >= %d", a, b)
+	}
+	if a, b := score("fxoo", "foo"), score("foox", "foo"); a >= b {
+		t.Errorf("Expected a consecutive match to score higher: %d >= %d", a, b)
+	}
+	if a, b := score("xfoo", "foo"), score("foo", "foo"); a >= b {
+		t.Errorf("Expected a match at the start to score higher: %d >= %d", a, b)
+	}
+}
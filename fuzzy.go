@@ -0,0 +1,149 @@
+package prompt
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// Scores used by FuzzyMatch, similar to the ones of fzf.
+const (
+	fuzzyScoreMatch        = 16
+	fuzzyScoreGapStart     = -3
+	fuzzyScoreGapExtension = -1
+
+	// bonus for matching a character after a whitespace or a delimiter
+	// or at the start of the text
+	fuzzyBonusBoundary = fuzzyScoreMatch / 2
+	// bonus for matching an upper case letter after a lower case one
+	// or a digit after a non-digit
+	fuzzyBonusCamelCase = fuzzyBonusBoundary - 1
+	// minimal bonus for matching a character right after the previous match
+	fuzzyBonusConsecutive = -(fuzzyScoreGapStart + fuzzyScoreGapExtension)
+	// the bonus of the first character of the pattern gets multiplied by this
+	fuzzyBonusFirstCharMultiplier = 2
+)
+
+const fuzzyDelimiters = `/\-_.,:;|=`
+
+const fuzzyNoScore = math.MinInt32
+
+// FuzzyMatch checks whether all runes of the pattern appear in the text
+// in the same order and scores the match like fzf does.
+// Matches at word boundaries (after whitespace, delimiters or at the start of the text),
+// at camelCase humps and consecutive matches score higher,
+// gaps between the matched runes lower the score.
+//
+// positions contains the indices of the matched runes of the text
+// in the best scoring match.
+func FuzzyMatch(text, pattern string, ignoreCase bool) (score int, positions []istrings.RuneNumber, ok bool) {
+	t := []rune(text)
+	p := []rune(pattern)
+	if len(p) == 0 {
+		return 0, nil, true
+	}
+	if len(p) > len(t) {
+		return 0, nil, false
+	}
+
+	equal := func(a, b rune) bool {
+		return a == b || ignoreCase && unicode.ToLower(a) == unicode.ToLower(b)
+	}
+
+	bonus := make([]int, len(t))
+	for j := range t {
+		if j == 0 {
+			bonus[j] = fuzzyBonusBoundary
+			continue
+		}
+		bonus[j] = fuzzyBonus(t[j-1], t[j])
+	}
+
+	// scores[i][j] is the best score of matching p[:i+1] with p[i] matched at t[j],
+	// previous[i][j] is the index in t where p[i-1] has been matched then
+	scores := make([][]int, len(p))
+	previous := make([][]int, len(p))
+	for i := range p {
+		scores[i] = make([]int, len(t))
+		previous[i] = make([]int, len(t))
+		// best score of matching p[:i] ending before j-1
+		// including the penalty for the gap up to j
+		bestGap := fuzzyNoScore
+		bestGapIndex := -1
+		for j := range t {
+			score := fuzzyNoScore
+			prev := -1
+			if equal(p[i], t[j]) {
+				switch {
+				case i == 0:
+					score = fuzzyScoreMatch + bonus[j]*fuzzyBonusFirstCharMultiplier
+				default:
+					if j > 0 && scores[i-1][j-1] != fuzzyNoScore {
+						b := bonus[j]
+						if b < fuzzyBonusConsecutive {
+							b = fuzzyBonusConsecutive
+						}
+						score = scores[i-1][j-1] + fuzzyScoreMatch + b
+						prev = j - 1
+					}
+					if bestGap != fuzzyNoScore {
+						if s := bestGap + fuzzyScoreMatch + bonus[j]; s > score {
+							score = s
+							prev = bestGapIndex
+						}
+					}
+				}
+			}
+			scores[i][j] = score
+			previous[i][j] = prev
+
+			if i == 0 {
+				continue
+			}
+			if bestGap != fuzzyNoScore {
+				bestGap += fuzzyScoreGapExtension
+			}
+			if j > 0 && scores[i-1][j-1] != fuzzyNoScore {
+				if s := scores[i-1][j-1] + fuzzyScoreGapStart; s > bestGap {
+					bestGap = s
+					bestGapIndex = j - 1
+				}
+			}
+		}
+	}
+
+	last := len(p) - 1
+	end := -1
+	score = fuzzyNoScore
+	for j, s := range scores[last] {
+		if s > score {
+			score = s
+			end = j
+		}
+	}
+	if end == -1 {
+		return 0, nil, false
+	}
+
+	positions = make([]istrings.RuneNumber, len(p))
+	for i, j := last, end; i >= 0; i-- {
+		positions[i] = istrings.RuneNumber(j)
+		j = previous[i][j]
+	}
+	return score, positions, true
+}
+
+// Returns the bonus for matching cur placed after prev.
+func fuzzyBonus(prev, cur rune) int {
+	switch {
+	case unicode.IsSpace(prev) || strings.ContainsRune(fuzzyDelimiters, prev):
+		return fuzzyBonusBoundary
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return fuzzyBonusCamelCase
+	case !unicode.IsDigit(prev) && unicode.IsDigit(cur):
+		return fuzzyBonusCamelCase
+	}
+	return 0
+}
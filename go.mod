@@ -8,11 +8,9 @@ require (
 	github.com/mattn/go-runewidth v0.0.9
 	github.com/mattn/go-tty v0.0.3
 	github.com/pkg/term v1.2.0-beta.2
+	github.com/rivo/uniseg v0.4.4
 	golang.org/x/exp v0.0.0-20230626212559-97b1e661b5df
 	golang.org/x/sys v0.1.0
 )
 
-require (
-	github.com/mattn/go-isatty v0.0.12 // indirect
-	github.com/rivo/uniseg v0.4.4 // indirect
-)
+require github.com/mattn/go-isatty v0.0.12 // indirect
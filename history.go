@@ -1,23 +1,136 @@
 package prompt
 
 import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/plandex-ai/go-prompt/debug"
 	istrings "github.com/plandex-ai/go-prompt/strings"
 )
 
+// HistoryControl is a set of flags that control
+// which inputs get added to the history, similar to HISTCONTROL of bash.
+type HistoryControl uint8
+
+const (
+	// HistoryIgnoreDups skips inputs equal to the previous history entry.
+	HistoryIgnoreDups HistoryControl = 1 << iota
+	// HistoryEraseDups removes all previous entries equal to the added input.
+	// Only the entries kept in memory are removed, history stores are append-only.
+	HistoryEraseDups
+	// HistoryIgnoreSpace skips inputs starting with a space.
+	HistoryIgnoreSpace
+	// HistoryIgnoreBoth is a shorthand for HistoryIgnoreDups and HistoryIgnoreSpace.
+	HistoryIgnoreBoth = HistoryIgnoreDups | HistoryIgnoreSpace
+)
+
 // History stores the texts that are entered.
 type History struct {
 	histories    []string
 	tmp          []string
 	selected     int
 	isNavigating bool
+	store        HistoryStore // store new entries are added to, nil when the history is kept only in memory
+	control      HistoryControl
+	ignore       []*regexp.Regexp // inputs matching any of these patterns are skipped
+	maxSize      int              // maximum number of entries kept in memory, 0 means no limit
+	prefixSearch bool             // whether Older and Newer walk only through the entries starting with prefix
+	prefix       string           // text before the cursor when the current walk through the history started
+	lastEntry    *HistoryEntry    // entry added to a HistoryExitStatusStore by the last call to AddEntry
 }
 
 // Add to add text in history.
 func (h *History) Add(input string) {
-	h.histories = append(h.histories, input)
+	h.AddEntry(HistoryEntry{Text: input, Timestamp: time.Now()})
+}
+
+// AddEntry adds the entry to the history and its store
+// unless it gets skipped by the history control policies.
+func (h *History) AddEntry(entry HistoryEntry) {
+	h.lastEntry = nil
+	if h.shouldAdd(entry.Text) {
+		h.push(entry.Text)
+		if h.store != nil {
+			debug.AssertNoError(h.store.Add(entry))
+		}
+		if _, ok := h.store.(HistoryExitStatusStore); ok {
+			h.lastEntry = &entry
+		}
+	}
+	h.Clear()
+}
+
+// Attaches the exit status to the entry added by the last call to AddEntry
+// when its store is a HistoryExitStatusStore.
+func (h *History) setExitStatus(exitStatus int) {
+	entry := h.lastEntry
+	h.lastEntry = nil
+	if entry == nil || entry.ExitStatus == exitStatus {
+		return
+	}
+	if store, ok := h.store.(HistoryExitStatusStore); ok {
+		debug.AssertNoError(store.SetExitStatus(*entry, exitStatus))
+	}
+}
+
+// Reports whether the input should be added to the history
+// according to the history control policies.
+func (h *History) shouldAdd(input string) bool {
+	if h.control&HistoryIgnoreSpace != 0 && strings.HasPrefix(input, " ") {
+		return false
+	}
+	if h.control&HistoryIgnoreDups != 0 &&
+		len(h.histories) > 0 && h.histories[len(h.histories)-1] == input {
+		return false
+	}
+	for _, re := range h.ignore {
+		if re.MatchString(input) {
+			return false
+		}
+	}
+	return true
+}
+
+// Appends the text to the entries kept in memory,
+// erasing duplicates and trimming the oldest entries when needed.
+func (h *History) push(text string) {
+	if h.control&HistoryEraseDups != 0 {
+		histories := h.histories[:0]
+		for _, entry := range h.histories {
+			if entry != text {
+				histories = append(histories, entry)
+			}
+		}
+		h.histories = histories
+	}
+	h.histories = append(h.histories, text)
+	if h.maxSize > 0 && len(h.histories) > h.maxSize {
+		h.histories = h.histories[len(h.histories)-h.maxSize:]
+	}
+}
+
+// Erases duplicates and trims the entries
+// that have been loaded before the history control policies were set.
+func (h *History) compact() {
+	histories := h.histories
+	h.histories = nil
+	for _, text := range histories {
+		if h.control&HistoryIgnoreDups != 0 &&
+			len(h.histories) > 0 && h.histories[len(h.histories)-1] == text {
+			continue
+		}
+		h.push(text)
+	}
 	h.Clear()
 }
 
+// Store returns the store of the history,
+// nil when the history is kept only in memory.
+func (h *History) Store() HistoryStore {
+	return h.store
+}
+
 // Clear to clear the history.
 func (h *History) Clear() {
 	h.tmp = make([]string, len(h.histories))
@@ -30,6 +143,9 @@ func (h *History) Clear() {
 // Older saves a buffer of current line and get a buffer of previous line by up-arrow.
 // The changes of line buffers are stored until new history is created.
 func (h *History) Older(buf *Buffer, columns istrings.Width, rows int) (new *Buffer, changed bool) {
+	if h.prefixSearch {
+		return h.olderWithPrefix(buf, columns, rows)
+	}
 	if len(h.tmp) == 1 || h.selected == 0 {
 		return buf, false
 	}
@@ -38,12 +154,16 @@ func (h *History) Older(buf *Buffer, columns istrings.Width, rows int) (new *Buf
 	h.isNavigating = true
 	new = NewBuffer()
 	new.InsertTextMoveCursor(h.tmp[h.selected], columns, rows, false)
+	new.clearEditHistory()
 	return new, true
 }
 
 // Newer saves a buffer of current line and get a buffer of next line by up-arrow.
 // The changes of line buffers are stored until new history is created.
 func (h *History) Newer(buf *Buffer, columns istrings.Width, rows int) (new *Buffer, changed bool) {
+	if h.prefixSearch {
+		return h.newerWithPrefix(buf, columns, rows)
+	}
 	if h.selected >= len(h.tmp)-1 {
 		h.isNavigating = false
 		return buf, false
@@ -52,9 +172,76 @@ func (h *History) Newer(buf *Buffer, columns istrings.Width, rows int) (new *Buf
 	h.selected++
 	new = NewBuffer()
 	new.InsertTextMoveCursor(h.tmp[h.selected], columns, rows, false)
+	new.clearEditHistory()
 	return new, true
 }
 
+// Gets a buffer of the previous line that starts with the text
+// that was before the cursor when the walk through the history started.
+func (h *History) olderWithPrefix(buf *Buffer, columns istrings.Width, rows int) (new *Buffer, changed bool) {
+	if !h.isNavigating {
+		h.prefix = buf.Document().TextBeforeCursor()
+	}
+	h.tmp[h.selected] = buf.Text()
+	for i := h.selected - 1; i >= 0; i-- {
+		if h.matchesPrefix(i) {
+			h.isNavigating = true
+			return h.selectWithPrefix(i, columns, rows), true
+		}
+	}
+	return buf, false
+}
+
+// Gets a buffer of the next line that starts with the text
+// that was before the cursor when the walk through the history started.
+// Returns to the line that was being edited when there are no more matches.
+func (h *History) newerWithPrefix(buf *Buffer, columns istrings.Width, rows int) (new *Buffer, changed bool) {
+	last := len(h.tmp) - 1
+	if h.selected >= last {
+		h.isNavigating = false
+		return buf, false
+	}
+	h.tmp[h.selected] = buf.Text()
+	for i := h.selected + 1; i < last; i++ {
+		if h.matchesPrefix(i) {
+			return h.selectWithPrefix(i, columns, rows), true
+		}
+	}
+	h.isNavigating = false
+	return h.selectWithPrefix(last, columns, rows), true
+}
+
+// Reports whether the line at the given index starts with the prefix,
+// is different from the line that is being edited
+// and is the newest occurrence of its text.
+func (h *History) matchesPrefix(index int) bool {
+	text := h.tmp[index]
+	if !strings.HasPrefix(text, h.prefix) {
+		return false
+	}
+	for _, newer := range h.tmp[index+1:] {
+		if newer == text {
+			return false
+		}
+	}
+	return true
+}
+
+// Selects the line at the given index and returns its buffer
+// with the cursor placed at the end of the prefix.
+func (h *History) selectWithPrefix(index int, columns istrings.Width, rows int) *Buffer {
+	h.selected = index
+	text := h.tmp[index]
+	cursor := istrings.RuneCountInString(h.prefix)
+	if length := istrings.RuneCountInString(text); cursor > length {
+		cursor = length
+	}
+	new := NewBuffer()
+	new.setDocument(&Document{Text: text, cursorPosition: cursor}, columns, rows)
+	new.clearEditHistory()
+	return new
+}
+
 // ResetNavigation resets the navigation state
 func (h *History) ResetNavigation() {
 	h.isNavigating = false
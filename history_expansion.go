@@ -0,0 +1,346 @@
+package prompt
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// HistoryExpansionError is returned when the history expansion
+// of an input fails.
+type HistoryExpansionError struct {
+	Designator string // part of the input that couldn't be expanded
+	Reason     string
+}
+
+func (e *HistoryExpansionError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Designator, e.Reason)
+}
+
+// ExpandHistory performs bash-style history expansion of the input
+// using the given history entries, the oldest one first.
+//
+// Supported event designators are !!, !n, !-n, !string and !?string?.
+// They can be followed by word designators like :0, :n, :^, :$, :*, :x-y, :x-, :-y and :x*,
+// the colon can be left out before ^, $ and *, so !$ stands for !!:$.
+// An input starting with ^old^new^ repeats the previous entry replacing old with new.
+// Text in single quotes and an exclamation mark preceded by a backslash or followed by
+// a whitespace, =, ( or " are not expanded.
+//
+// The second value reports whether anything has been expanded.
+func ExpandHistory(input string, histories []string) (expanded string, changed bool, err error) {
+	if strings.HasPrefix(input, "^") {
+		return expandQuickSubstitution(input, histories)
+	}
+
+	var b strings.Builder
+	inSingleQuote := false
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case c == '\'':
+			inSingleQuote = !inSingleQuote
+		case inSingleQuote:
+		case c == '\\' && i+1 < len(input):
+			b.WriteByte(c)
+			i++
+			c = input[i]
+		case c == '!' && isHistoryEventStart(input, i+1):
+			text, end, err := expandHistoryEvent(input, i, histories)
+			if err != nil {
+				return input, false, err
+			}
+			b.WriteString(text)
+			changed = true
+			i = end - 1
+			continue
+		}
+		b.WriteByte(c)
+	}
+
+	return b.String(), changed, nil
+}
+
+// Reports whether an exclamation mark followed by the character
+// at the given index starts a history expansion.
+func isHistoryEventStart(input string, i int) bool {
+	if i >= len(input) {
+		return false
+	}
+	switch c := input[i]; c {
+	case '=', '(', '"':
+		return false
+	default:
+		return !unicode.IsSpace(rune(c))
+	}
+}
+
+// Expands the event designator starting at the exclamation mark at the given index
+// together with its word designator. Returns the expanded text
+// and the index of the first byte after the designators.
+func expandHistoryEvent(input string, start int, histories []string) (text string, end int, err error) {
+	i := start + 1
+	var event string
+	found := false
+	allowShortWordDesignator := true
+
+	switch c := input[i]; {
+	case c == '!':
+		i++
+		event, found = historyEventFromEnd(histories, 1)
+	case c == '$' || c == '^' || c == '*' || c == ':':
+		event, found = historyEventFromEnd(histories, 1)
+	case c == '-' || isDigit(c):
+		j := i
+		if c == '-' {
+			j++
+		}
+		for j < len(input) && isDigit(input[j]) {
+			j++
+		}
+		n, convErr := strconv.Atoi(input[i:j])
+		if convErr != nil {
+			return "", 0, &HistoryExpansionError{Designator: input[start:j], Reason: "event not found"}
+		}
+		i = j
+		if n < 0 {
+			event, found = historyEventFromEnd(histories, -n)
+		} else if n > 0 && n <= len(histories) {
+			event, found = histories[n-1], true
+		}
+	case c == '?':
+		j := strings.IndexByte(input[i+1:], '?')
+		var query string
+		if j == -1 {
+			query = input[i+1:]
+			i = len(input)
+		} else {
+			query = input[i+1 : i+1+j]
+			i += j + 2
+		}
+		allowShortWordDesignator = false
+		for k := len(histories) - 1; k >= 0; k-- {
+			if strings.Contains(histories[k], query) {
+				event, found = histories[k], true
+				break
+			}
+		}
+	default:
+		j := i
+		for j < len(input) && input[j] != ':' && !unicode.IsSpace(rune(input[j])) {
+			j++
+		}
+		prefix := input[i:j]
+		i = j
+		allowShortWordDesignator = false
+		for k := len(histories) - 1; k >= 0; k-- {
+			if strings.HasPrefix(histories[k], prefix) {
+				event, found = histories[k], true
+				break
+			}
+		}
+	}
+	if !found {
+		return "", 0, &HistoryExpansionError{Designator: input[start:i], Reason: "event not found"}
+	}
+
+	// word designator
+	switch {
+	case i < len(input) && input[i] == ':' && i+1 < len(input) && isWordDesignatorStart(input[i+1]):
+		i++
+	case allowShortWordDesignator && i < len(input) && strings.IndexByte("^$*", input[i]) != -1:
+	default:
+		return event, i, nil
+	}
+
+	words := splitHistoryWords(event)
+	from, to, i, ok := parseWordDesignator(input, i, len(words))
+	if !ok || from < 0 || to >= len(words) || (from > to && to != from-1) {
+		return "", 0, &HistoryExpansionError{Designator: input[start:i], Reason: "bad word specifier"}
+	}
+	return strings.Join(words[from:to+1], " "), i, nil
+}
+
+// Returns the n-th entry counting from the newest one.
+func historyEventFromEnd(histories []string, n int) (string, bool) {
+	if n <= 0 || n > len(histories) {
+		return "", false
+	}
+	return histories[len(histories)-n], true
+}
+
+func isWordDesignatorStart(c byte) bool {
+	return isDigit(c) || strings.IndexByte("^$*-", c) != -1
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// Parses the word designator starting at the given index
+// and returns the range of words it selects (inclusive)
+// and the index of the first byte after it.
+// An empty range is returned as from = to + 1.
+func parseWordDesignator(input string, i int, wordCount int) (from, to, end int, ok bool) {
+	last := wordCount - 1
+	parseNumber := func() (int, bool) {
+		j := i
+		for j < len(input) && isDigit(input[j]) {
+			j++
+		}
+		if j == i {
+			return 0, false
+		}
+		n, err := strconv.Atoi(input[i:j])
+		i = j
+		return n, err == nil
+	}
+
+	switch input[i] {
+	case '^':
+		return 1, 1, i + 1, true
+	case '$':
+		return last, last, i + 1, true
+	case '*':
+		if wordCount < 2 {
+			return 1, 0, i + 1, true
+		}
+		return 1, last, i + 1, true
+	case '-':
+		i++
+		from = 0
+	default:
+		from, ok = parseNumber()
+		if !ok {
+			return 0, 0, i, false
+		}
+		if i >= len(input) || (input[i] != '-' && input[i] != '*') {
+			return from, from, i, true
+		}
+		if input[i] == '*' {
+			if from == wordCount {
+				return from, from - 1, i + 1, true
+			}
+			return from, last, i + 1, true
+		}
+		i++
+	}
+
+	// the end of the range after a dash
+	switch {
+	case i < len(input) && input[i] == '$':
+		return from, last, i + 1, true
+	case i < len(input) && isDigit(input[i]):
+		to, ok = parseNumber()
+		return from, to, i, ok
+	default:
+		// x- abbreviates x-$ without the last word
+		return from, last - 1, i, true
+	}
+}
+
+// Splits the entry into words separated by whitespace,
+// whitespace in quotes or escaped by a backslash doesn't separate words.
+func splitHistoryWords(entry string) []string {
+	var words []string
+	var word strings.Builder
+	var quote rune
+	inWord := false
+	escaped := false
+	for _, char := range entry {
+		switch {
+		case escaped:
+			escaped = false
+		case char == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"':
+			quote = char
+		case unicode.IsSpace(char):
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+			continue
+		}
+		word.WriteRune(char)
+		inWord = true
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// Expands ^old^new^ into the previous entry with
+// the first occurrence of old replaced by new.
+// The text after the last caret is appended.
+func expandQuickSubstitution(input string, histories []string) (expanded string, changed bool, err error) {
+	parts := strings.SplitN(input[1:], "^", 3)
+	old := parts[0]
+	var new, rest string
+	if len(parts) > 1 {
+		new = parts[1]
+	}
+	if len(parts) > 2 {
+		rest = parts[2]
+	}
+
+	event, ok := historyEventFromEnd(histories, 1)
+	if !ok {
+		return input, false, &HistoryExpansionError{Designator: input, Reason: "event not found"}
+	}
+	if old == "" || !strings.Contains(event, old) {
+		return input, false, &HistoryExpansionError{Designator: input, Reason: "substitution failed"}
+	}
+	return strings.Replace(event, old, new, 1) + rest, true, nil
+}
+
+// Expand performs bash-style history expansion of the input
+// using the entries of the history (see ExpandHistory).
+func (h *History) Expand(input string) (expanded string, changed bool, err error) {
+	return ExpandHistory(input, h.histories)
+}
+
+// ExpandHistoryInPlace Perform the history expansion of the buffer (see ExpandHistory)
+func ExpandHistoryInPlace(p *Prompt) bool {
+	expanded, changed, err := p.history.Expand(p.buffer.Text())
+	if err != nil || !changed {
+		return false
+	}
+	p.buffer.beginEdit(editOther)
+	p.buffer.setDocument(
+		&Document{Text: expanded, cursorPosition: istrings.RuneCountInString(expanded)},
+		p.renderer.UserInputColumns(),
+		p.renderer.row,
+	)
+	p.buffer.endEdit()
+	return true
+}
+
+// Expands the history in the buffer before it gets executed.
+// Returns false when the expansion fails, the error gets printed
+// and the input doesn't get executed then.
+func (p *Prompt) expandHistoryOnEnter() bool {
+	expanded, changed, err := p.history.Expand(p.buffer.Text())
+	if err != nil {
+		p.renderer.BreakLine(p.buffer, p.lexer)
+		p.renderer.renderMessage(err.Error())
+		return false
+	}
+	if changed {
+		p.buffer.setDocument(
+			&Document{Text: expanded, cursorPosition: istrings.RuneCountInString(expanded)},
+			p.renderer.UserInputColumns(),
+			p.renderer.row,
+		)
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package prompt
+
+import (
+	"testing"
+)
+
+func TestExpandHistory(t *testing.T) {
+	histories := []string{
+		"git commit -m 'first commit'",
+		"ls -la /tmp",
+		`echo "foo bar" baz`,
+		"cat a.txt b.txt c.txt",
+	}
+
+	tests := map[string]struct {
+		input   string
+		want    string
+		changed bool
+		err     string
+	}{
+		"no expansion":               {input: "echo hi", want: "echo hi"},
+		"previous entry":             {input: "sudo !!", want: "sudo cat a.txt b.txt c.txt", changed: true},
+		"absolute entry":             {input: "!2", want: "ls -la /tmp", changed: true},
+		"relative entry":             {input: "!-2", want: `echo "foo bar" baz`, changed: true},
+		"prefix":                     {input: "!ls", want: "ls -la /tmp", changed: true},
+		"substring":                  {input: "!?first?", want: "git commit -m 'first commit'", changed: true},
+		"last argument":              {input: "vim !$", want: "vim c.txt", changed: true},
+		"first argument":             {input: "vim !^", want: "vim a.txt", changed: true},
+		"all arguments":              {input: "rm !*", want: "rm a.txt b.txt c.txt", changed: true},
+		"word of an entry":           {input: "cd !2:2", want: "cd /tmp", changed: true},
+		"quoted words":               {input: "echo !-2:1", want: `echo "foo bar"`, changed: true},
+		"word range":                 {input: "!!:1-2", want: "a.txt b.txt", changed: true},
+		"word range to the end":      {input: "!!:2*", want: "b.txt c.txt", changed: true},
+		"word range without last":    {input: "!!:0-", want: "cat a.txt b.txt", changed: true},
+		"word range from the start":  {input: "!!:-1", want: "cat a.txt", changed: true},
+		"last word of prefix event":  {input: "!git:$", want: "'first commit'", changed: true},
+		"multiple expansions":        {input: "!1:0 !!:0", want: "git cat", changed: true},
+		"quick substitution":         {input: "^a.txt^d.txt", want: "cat d.txt b.txt c.txt", changed: true},
+		"quick substitution rest":    {input: "^cat^head^ -n 1", want: "head a.txt b.txt c.txt -n 1", changed: true},
+		"single quotes":              {input: "echo '!!'", want: "echo '!!'"},
+		"escaped":                    {input: `echo \!!`, want: `echo \!!`},
+		"not followed by designator": {input: "echo hi! a != b", want: "echo hi! a != b"},
+		"missing event":              {input: "!foo", want: "!foo", err: "!foo: event not found"},
+		"out of range event":         {input: "!10", want: "!10", err: "!10: event not found"},
+		"bad word":                   {input: "!!:5", want: "!!:5", err: "!!:5: bad word specifier"},
+		"failed substitution":        {input: "^zz^y", want: "^zz^y", err: "^zz^y: substitution failed"},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, changed, err := ExpandHistory(tc.input, histories)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("Want error %q, but got %v", tc.err, err)
+				}
+			} else if err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+			if got != tc.want {
+				t.Errorf("Want %q, but got %q", tc.want, got)
+			}
+			if changed != tc.changed {
+				t.Errorf("Want changed %t, but got %t", tc.changed, changed)
+			}
+		})
+	}
+}
+
+func TestHistoryExpansionOnEnter(t *testing.T) {
+	p := newTestPrompt(WithHistory([]string{"ls /tmp"}), WithHistoryExpansion())
+
+	input := feedAll(p, "cd !$", "\n")
+	if input == nil || input.input != "cd /tmp" {
+		t.Errorf("Want user input %q, but got %#v", "cd /tmp", input)
+	}
+
+	// the input isn't executed when the expansion fails
+	input = feedAll(p, "!foo", "\n")
+	if input != nil {
+		t.Errorf("Want no user input, but got %#v", input)
+	}
+	if got := p.buffer.Text(); got != "!foo" {
+		t.Errorf("Want %q, but got %q", "!foo", got)
+	}
+}
+
+func TestExpandHistoryInPlace(t *testing.T) {
+	p := newTestPrompt(WithHistory([]string{"ls /tmp"}))
+	feedAll(p, "cd !$")
+	if !ExpandHistoryInPlace(p) {
+		t.Errorf("Want the buffer to be changed")
+	}
+	if got := p.buffer.Text(); got != "cd /tmp" {
+		t.Errorf("Want %q, but got %q", "cd /tmp", got)
+	}
+	if got := p.buffer.cursorPosition; got != 7 {
+		t.Errorf("Want cursor 7, but got %d", got)
+	}
+	p.Undo()
+	if got := p.buffer.Text(); got != "cd !$" {
+		t.Errorf("Want %q, but got %q", "cd !$", got)
+	}
+}
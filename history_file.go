@@ -0,0 +1,356 @@
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// DefaultHistoryFileMaxSize is the default maximum number of entries
+// kept in a history file.
+const DefaultHistoryFileMaxSize = 1000
+
+// HistoryFileOption is the type of options of file-backed history stores.
+type HistoryFileOption func(*historyFileConfig)
+
+// WithHistoryFileMaxSize sets the maximum number of entries kept in the history file.
+// The oldest entries are removed when the file grows larger.
+// Zero means that the file is never trimmed.
+func WithHistoryFileMaxSize(size int) HistoryFileOption {
+	return func(c *historyFileConfig) {
+		c.maxSize = size
+	}
+}
+
+// WithHistoryFilePermissions sets the permissions
+// used when the history file gets created.
+func WithHistoryFilePermissions(perm os.FileMode) HistoryFileOption {
+	return func(c *historyFileConfig) {
+		c.perm = perm
+	}
+}
+
+// historyFileConfig describes a file that stores one history entry per line.
+// The file is locked while it's being read or written
+// so that it can be shared by several concurrent sessions.
+type historyFileConfig struct {
+	path    string
+	maxSize int
+	perm    os.FileMode
+	// Rewrites the lines of a file that contains lines other than entries
+	// so that every line is an entry. Only entries count toward maxSize.
+	fold func(lines []string) []string
+
+	// size and number of entries of the file after the last append,
+	// used to skip counting the entries when no other session has changed the file
+	size  int64
+	lines int
+}
+
+func newHistoryFileConfig(path string, opts []HistoryFileOption) historyFileConfig {
+	c := historyFileConfig{
+		path:    path,
+		maxSize: DefaultHistoryFileMaxSize,
+		perm:    0600,
+		lines:   -1,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// Opens and locks the file. When another session has replaced the file
+// while waiting for the lock, the new file gets opened instead.
+func (c *historyFileConfig) open(flag int, exclusive bool) (*os.File, error) {
+	for {
+		file, err := os.OpenFile(c.path, flag, c.perm)
+		if err != nil {
+			return nil, err
+		}
+		if err := lockFile(file, exclusive); err != nil {
+			file.Close()
+			return nil, err
+		}
+
+		opened, err := file.Stat()
+		if err != nil {
+			unlockFile(file)
+			file.Close()
+			return nil, err
+		}
+		current, err := os.Stat(c.path)
+		if err != nil || os.SameFile(opened, current) {
+			return file, nil
+		}
+		unlockFile(file)
+		file.Close()
+	}
+}
+
+// Reads all entries of the file, the oldest one first.
+// A missing file is treated like an empty one.
+func (c *historyFileConfig) readLines() ([]string, error) {
+	file, err := c.open(os.O_RDONLY, false)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	defer unlockFile(file)
+
+	lines, err := c.readEntries(file)
+	if err != nil {
+		return nil, err
+	}
+	if c.maxSize > 0 && len(lines) > c.maxSize {
+		lines = lines[len(lines)-c.maxSize:]
+	}
+	return lines, nil
+}
+
+// Reads the lines of the file folded into entries.
+func (c *historyFileConfig) readEntries(file *os.File) ([]string, error) {
+	lines, err := readLines(file)
+	if err != nil || c.fold == nil {
+		return lines, err
+	}
+	return c.fold(lines), nil
+}
+
+// Adds the line to the end of the file and trims the file when it exceeds
+// its maximum size by more than a tenth, so that it doesn't get
+// rewritten after every append. Lines that aren't entries
+// don't count toward the size.
+func (c *historyFileConfig) appendLine(line string, entry bool) (err error) {
+	file, err := c.open(os.O_RDWR|os.O_CREATE|os.O_APPEND, true)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	defer unlockFile(file)
+
+	lines, err := c.countEntries(file)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString(line + "\n"); err != nil {
+		c.lines = -1
+		return err
+	}
+	c.size += int64(len(line)) + 1
+	c.lines = lines
+	if entry {
+		c.lines++
+	}
+
+	if c.maxSize <= 0 || c.lines <= c.maxSize+(c.maxSize+9)/10 {
+		return nil
+	}
+	return c.trim(file)
+}
+
+// Returns the number of entries of the locked file.
+// The file is only read when its size differs from the one after the last append.
+func (c *historyFileConfig) countEntries(file *os.File) (int, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if c.lines >= 0 && info.Size() == c.size {
+		return c.lines, nil
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	lines, err := c.readEntries(file)
+	if err != nil {
+		return 0, err
+	}
+	c.size = info.Size()
+	return len(lines), nil
+}
+
+// Keeps only the newest entries of the locked file.
+// The lines are written to a temporary file that replaces the original one
+// so that the history doesn't get lost when the process crashes meanwhile.
+func (c *historyFileConfig) trim(file *os.File) error {
+	c.lines = -1
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	lines, err := c.readEntries(file)
+	if err != nil {
+		return err
+	}
+	if len(lines) <= c.maxSize {
+		return nil
+	}
+
+	lines = lines[len(lines)-c.maxSize:]
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	if err := replaceFile(file, c.path, b.String()); err != nil {
+		return err
+	}
+	c.size = int64(b.Len())
+	c.lines = len(lines)
+	return nil
+}
+
+// Reads the non-empty lines.
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSuffix(line, "\n")
+		line = strings.TrimSuffix(line, "\r")
+		if line != "" {
+			lines = append(lines, line)
+		}
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// HistoryFile is a HistoryStore that keeps the texts of entries in a plain file,
+// one entry per line. Backslashes and line breaks in entries are escaped
+// so that multi-line entries take a single line in the file.
+// The metadata of entries is not stored.
+// Like JSONLinesHistoryStore, it can be shared by concurrent sessions.
+type HistoryFile struct {
+	config historyFileConfig
+}
+
+var _ HistoryStore = &HistoryFile{}
+
+// NewHistoryFile returns a new history file stored at the given path.
+// The file gets created when the first entry is appended.
+func NewHistoryFile(path string, opts ...HistoryFileOption) *HistoryFile {
+	return &HistoryFile{
+		config: newHistoryFileConfig(path, opts),
+	}
+}
+
+// Path returns the path of the history file.
+func (f *HistoryFile) Path() string {
+	return f.config.path
+}
+
+// Load reads all entries of the history file, the oldest one first.
+// A missing file is treated like an empty one.
+func (f *HistoryFile) Load() ([]string, error) {
+	lines, err := f.config.readLines()
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range lines {
+		lines[i] = unescapeHistoryEntry(line)
+	}
+	return lines, nil
+}
+
+// Append adds the entry to the end of the history file
+// and trims the file when it exceeds its maximum size.
+func (f *HistoryFile) Append(entry string) error {
+	return f.config.appendLine(escapeHistoryEntry(entry), true)
+}
+
+// Add appends the text of the entry to the history file.
+func (f *HistoryFile) Add(entry HistoryEntry) error {
+	return f.Append(entry.Text)
+}
+
+// Iterate calls fn for every entry, the oldest one first,
+// until fn returns false.
+func (f *HistoryFile) Iterate(fn func(entry HistoryEntry) bool) error {
+	texts, err := f.Load()
+	if err != nil {
+		return err
+	}
+	for _, text := range texts {
+		if !fn(HistoryEntry{Text: text}) {
+			break
+		}
+	}
+	return nil
+}
+
+// Search returns the entries that contain the query, the oldest one first.
+func (f *HistoryFile) Search(query string) ([]HistoryEntry, error) {
+	return searchHistoryStore(f, query)
+}
+
+// Len returns the number of entries in the history file.
+func (f *HistoryFile) Len() (int, error) {
+	texts, err := f.Load()
+	return len(texts), err
+}
+
+// Escapes backslashes and line breaks so that the entry fits in a single line.
+func escapeHistoryEntry(entry string) string {
+	var b strings.Builder
+	for _, char := range entry {
+		switch char {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			b.WriteRune(char)
+		}
+	}
+	return b.String()
+}
+
+// Reverses escapeHistoryEntry. Unknown escape sequences are left as they are.
+func unescapeHistoryEntry(line string) string {
+	var b strings.Builder
+	escaped := false
+	for _, char := range line {
+		if !escaped {
+			if char == '\\' {
+				escaped = true
+			} else {
+				b.WriteRune(char)
+			}
+			continue
+		}
+
+		escaped = false
+		switch char {
+		case '\\':
+			b.WriteRune('\\')
+		case 'n':
+			b.WriteRune('\n')
+		case 'r':
+			b.WriteRune('\r')
+		default:
+			b.WriteRune('\\')
+			b.WriteRune(char)
+		}
+	}
+	if escaped {
+		b.WriteRune('\\')
+	}
+	return b.String()
+}
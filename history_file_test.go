@@ -0,0 +1,170 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestHistoryEntryEscaping(t *testing.T) {
+	tests := map[string]string{
+		"foo":              "foo",
+		"foo\nbar":         `foo\nbar`,
+		`C:\new`:           `C:\\new`,
+		"a\\\nb\r\n":       `a\\\nb\r\n`,
+		`trailing \`:       `trailing \\`,
+		"日本語\n\\n":         `日本語\n\\n`,
+		"select *\n  from": `select *\n  from`,
+	}
+	for entry, want := range tests {
+		got := escapeHistoryEntry(entry)
+		if got != want {
+			t.Errorf("escape %q: want %q, but got %q", entry, want, got)
+		}
+		if unescaped := unescapeHistoryEntry(got); unescaped != entry {
+			t.Errorf("unescape %q: want %q, but got %q", got, entry, unescaped)
+		}
+	}
+
+	if got := unescapeHistoryEntry(`\x \`); got != `\x \` {
+		t.Errorf("Want unknown escape sequences to be kept, but got %q", got)
+	}
+}
+
+func TestHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	f := NewHistoryFile(path, WithHistoryFileMaxSize(3))
+
+	entries, err := f.Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Want no entries, but got %#v", entries)
+	}
+
+	for _, entry := range []string{"zeroth", "first", "second\nline", "third"} {
+		if err := f.Append(entry); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	entries, err = f.Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if want := []string{"first", "second\nline", "third"}; !reflect.DeepEqual(want, entries) {
+		t.Errorf("Want %#v, but got %#v", want, entries)
+	}
+
+	// the file gets trimmed only when it exceeds the maximum size by more than a tenth
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if want := "zeroth\nfirst\nsecond\\nline\nthird\n"; string(content) != want {
+		t.Errorf("Want %q, but got %q", want, content)
+	}
+
+	if err := f.Append("fourth"); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	content, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if want := "second\\nline\nthird\nfourth\n"; string(content) != want {
+		t.Errorf("Want %q, but got %q", want, content)
+	}
+}
+
+func TestHistoryFileTrimWithConcurrentSessions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	first := NewHistoryFile(path, WithHistoryFileMaxSize(3))
+	second := NewHistoryFile(path, WithHistoryFileMaxSize(3))
+
+	// the sessions notice the entries appended by each other
+	for i, entry := range []string{"a", "b", "c", "d", "e", "f"} {
+		f := first
+		if i%2 == 1 {
+			f = second
+		}
+		if err := f.Append(entry); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if want := "c\nd\ne\nf\n"; string(content) != want {
+		t.Errorf("Want %q, but got %q", want, content)
+	}
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(matches) != 0 {
+		t.Errorf("Want no temporary files, but got %#v", matches)
+	}
+}
+
+func TestHistoryFileConcurrentAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	const sessions, count = 4, 25
+
+	var wg sync.WaitGroup
+	for i := 0; i < sessions; i++ {
+		wg.Add(1)
+		go func(session int) {
+			defer wg.Done()
+			f := NewHistoryFile(path, WithHistoryFileMaxSize(0))
+			for j := 0; j < count; j++ {
+				if err := f.Append(fmt.Sprintf("session %d\nentry %d", session, j)); err != nil {
+					t.Errorf("Unexpected error: %s", err)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	entries, err := NewHistoryFile(path).Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	var want []string
+	for i := 0; i < sessions; i++ {
+		for j := 0; j < count; j++ {
+			want = append(want, fmt.Sprintf("session %d\nentry %d", i, j))
+		}
+	}
+	sort.Strings(want)
+	sort.Strings(entries)
+	if !reflect.DeepEqual(want, entries) {
+		t.Errorf("Want %d entries, but got %d: %#v", len(want), len(entries), entries)
+	}
+}
+
+func TestWithHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	if err := os.WriteFile(path, []byte("foo\nbar\\nbaz\n"), 0600); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	p := newTestPrompt(WithHistoryFile(path))
+	if want := []string{"foo", "bar\nbaz"}; !reflect.DeepEqual(want, p.history.histories) {
+		t.Errorf("Want %#v, but got %#v", want, p.history.histories)
+	}
+
+	feedAll(p, "qux", "\n")
+	entries, err := NewHistoryFile(path).Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if want := []string{"foo", "bar\nbaz", "qux"}; !reflect.DeepEqual(want, entries) {
+		t.Errorf("Want %#v, but got %#v", want, entries)
+	}
+}
@@ -0,0 +1,38 @@
+//go:build windows
+// +build windows
+
+package prompt
+
+import (
+	"math"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile blocks until a lock of the whole file is acquired.
+func lockFile(f *os.File, exclusive bool) error {
+	var flags uint32
+	if exclusive {
+		flags = windows.LOCKFILE_EXCLUSIVE_LOCK
+	}
+	return windows.LockFileEx(windows.Handle(f.Fd()), flags, 0, math.MaxUint32, math.MaxUint32, new(windows.Overlapped))
+}
+
+// unlockFile releases the lock acquired by lockFile.
+func unlockFile(f *os.File) error {
+	return windows.UnlockFileEx(windows.Handle(f.Fd()), 0, math.MaxUint32, math.MaxUint32, new(windows.Overlapped))
+}
+
+// replaceFile replaces the content of the locked file.
+// A file that is open can't be renamed over on Windows,
+// so the content gets rewritten in place.
+func replaceFile(f *os.File, path, content string) error {
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	// the file is opened in the append mode
+	// so the content gets written at the start of the truncated file
+	_, err := f.WriteString(content)
+	return err
+}
@@ -0,0 +1,208 @@
+package prompt
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// historySearch is the state of the incremental history search
+// started by Ctrl+R or Ctrl+S.
+type historySearch struct {
+	query      string
+	original   *Buffer             // buffer that gets restored when the search is aborted
+	index      int                 // index of the matched entry in History.histories, -1 when nothing has been matched
+	matchStart istrings.RuneNumber // index of the first rune of the match in the entry
+	failed     bool                // whether the last search didn't find anything
+	forward    bool
+}
+
+// Returns the prompt displayed in place of the prefix during the search.
+func (s *historySearch) prompt() string {
+	var b strings.Builder
+	b.WriteByte('(')
+	if s.failed {
+		b.WriteString("failed ")
+	}
+	if s.forward {
+		b.WriteString("i-search")
+	} else {
+		b.WriteString("reverse-i-search")
+	}
+	b.WriteString(")`")
+	b.WriteString(s.query)
+	b.WriteString("': ")
+	return b.String()
+}
+
+// Searches the histories for the query starting at the given index
+// and moving in the direction of the search. Entries with the same text
+// as the current match are skipped unless the search starts at the current match.
+// Returns false when nothing has been found, the current match is kept then.
+func (s *historySearch) find(histories []string, from int) bool {
+	if s.query == "" {
+		s.failed = false
+		return false
+	}
+	step := -1
+	if s.forward {
+		step = 1
+	}
+	for i := from; i >= 0 && i < len(histories); i += step {
+		if s.index != -1 && i != s.index && histories[i] == histories[s.index] {
+			continue
+		}
+		if idx := strings.Index(histories[i], s.query); idx != -1 {
+			s.index = i
+			s.matchStart = istrings.RuneCountInString(histories[i][:idx])
+			s.failed = false
+			return true
+		}
+	}
+	s.failed = true
+	return false
+}
+
+// Returns a lexer that highlights the match in the displayed entry.
+func (s *historySearch) lexer(r *Renderer) Lexer {
+	return NewEagerLexer(func(input string) []Token {
+		if s.index == -1 || s.query == "" {
+			return nil
+		}
+		runes := []rune(input)
+		if int(s.matchStart) > len(runes) {
+			return nil
+		}
+		start := len(string(runes[:s.matchStart]))
+		if !strings.HasPrefix(input[start:], s.query) {
+			return nil
+		}
+		return []Token{
+			NewSimpleToken(
+				istrings.ByteNumber(start),
+				istrings.ByteNumber(start+len(s.query)-1),
+				SimpleTokenWithColor(r.historySearchMatchTextColor),
+				SimpleTokenWithBackgroundColor(r.historySearchMatchBGColor),
+			),
+		}
+	})
+}
+
+// ReverseSearchHistory Start the incremental search through the history
+// towards older entries or move to the next older match
+func ReverseSearchHistory(p *Prompt) bool {
+	return p.searchHistory(false)
+}
+
+// ForwardSearchHistory Start the incremental search through the history
+// towards newer entries or move to the next newer match
+func ForwardSearchHistory(p *Prompt) bool {
+	return p.searchHistory(true)
+}
+
+func (p *Prompt) searchHistory(forward bool) bool {
+	histories := p.history.histories
+	s := p.search
+	if s == nil {
+		p.search = &historySearch{
+			original: p.buffer,
+			index:    -1,
+			forward:  forward,
+		}
+		p.completion.Reset()
+		return true
+	}
+
+	s.forward = forward
+	if s.query == "" {
+		// repeat the previous search
+		s.query = p.lastHistorySearchQuery
+	}
+	from := s.index
+	switch {
+	case forward && s.index == -1:
+		return true
+	case forward:
+		from++
+	case s.index == -1:
+		from = len(histories) - 1
+	default:
+		from--
+	}
+	if s.find(histories, from) {
+		p.showHistorySearchMatch()
+	}
+	return true
+}
+
+// Handles a key pressed during the history search.
+// Returns false when the search has been accepted and the key
+// should be processed as usual.
+func (p *Prompt) handleHistorySearchKey(b []byte, key Key) bool {
+	s := p.search
+	histories := p.history.histories
+
+	switch key {
+	case ControlR:
+		ReverseSearchHistory(p)
+	case ControlS:
+		ForwardSearchHistory(p)
+	case ControlG:
+		p.buffer = s.original
+		p.search = nil
+	case Backspace, ControlH:
+		if s.query == "" {
+			break
+		}
+		_, size := utf8.DecodeLastRuneInString(s.query)
+		s.query = s.query[:len(s.query)-size]
+		p.lastHistorySearchQuery = s.query
+		// search again from the newest entry
+		s.index = -1
+		s.forward = false
+		s.find(histories, len(histories)-1)
+		p.showHistorySearchMatch()
+	case NotDefined:
+		if char, _ := utf8.DecodeRune(b); unicode.IsControl(char) {
+			p.search = nil
+			return false
+		}
+		s.query += string(b)
+		p.lastHistorySearchQuery = s.query
+		from := s.index
+		if from == -1 {
+			from = len(histories) - 1
+			if s.forward {
+				from = 0
+			}
+		}
+		if s.find(histories, from) {
+			p.showHistorySearchMatch()
+		}
+	default:
+		// accept the match and process the key as usual
+		p.search = nil
+		return false
+	}
+	return true
+}
+
+// Replaces the buffer with the matched entry and places the cursor
+// at the start of the match.
+func (p *Prompt) showHistorySearchMatch() {
+	s := p.search
+	if s.index == -1 {
+		p.buffer = s.original
+		return
+	}
+
+	text := p.history.histories[s.index]
+	p.buffer = NewBuffer()
+	p.buffer.setDocument(
+		&Document{Text: text, cursorPosition: s.matchStart},
+		p.renderer.UserInputColumns(),
+		p.renderer.row,
+	)
+}
@@ -0,0 +1,114 @@
+package prompt
+
+import (
+	"testing"
+
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+func TestHistorySearch(t *testing.T) {
+	const (
+		controlG = "\x07"
+		controlR = "\x12"
+		controlS = "\x13"
+	)
+	p := newTestPrompt(WithHistory([]string{"git status", "go test ./...", "git commit", "ls"}))
+	feedAll(p, "foo")
+
+	feedAll(p, controlR)
+	if p.search == nil {
+		t.Fatalf("Want the history search to be started")
+	}
+	feedAll(p, "gi")
+	if got := p.buffer.Text(); got != "git commit" {
+		t.Errorf("Want %q, but got %q", "git commit", got)
+	}
+	if got := p.search.prompt(); got != "(reverse-i-search)`gi': " {
+		t.Errorf("Want %q, but got %q", "(reverse-i-search)`gi': ", got)
+	}
+
+	// cycle through the matches
+	feedAll(p, controlR)
+	if got := p.buffer.Text(); got != "git status" {
+		t.Errorf("Want %q, but got %q", "git status", got)
+	}
+	feedAll(p, controlR)
+	if got := p.search.prompt(); got != "(failed reverse-i-search)`gi': " {
+		t.Errorf("Want %q, but got %q", "(failed reverse-i-search)`gi': ", got)
+	}
+	feedAll(p, controlS)
+	if got := p.buffer.Text(); got != "git commit" {
+		t.Errorf("Want %q, but got %q", "git commit", got)
+	}
+
+	// narrow the matches
+	feedAll(p, "t s")
+	if got := p.search.prompt(); got != "(failed i-search)`git s': " {
+		t.Errorf("Want %q, but got %q", "(failed i-search)`git s': ", got)
+	}
+	feedAll(p, "\x7f", "\x7f", controlR)
+	if got := p.buffer.Text(); got != "git status" {
+		t.Errorf("Want %q, but got %q", "git status", got)
+	}
+
+	// abort the search
+	feedAll(p, controlG)
+	if p.search != nil {
+		t.Errorf("Want the history search to be stopped")
+	}
+	if got := p.buffer.Text(); got != "foo" {
+		t.Errorf("Want %q, but got %q", "foo", got)
+	}
+
+	// repeat the previous search and accept the match
+	feedAll(p, controlR, controlR)
+	if got := p.buffer.Text(); got != "git commit" {
+		t.Errorf("Want %q, but got %q", "git commit", got)
+	}
+	input := feedAll(p, "\n")
+	if input == nil || input.input != "git commit" {
+		t.Errorf("Want user input %q, but got %#v", "git commit", input)
+	}
+}
+
+func TestHistorySearchAcceptsOnOtherKeys(t *testing.T) {
+	p := newTestPrompt(WithHistory([]string{"echo foo bar"}))
+	feedAll(p, "\x12", "bar")
+	if got := p.buffer.cursorPosition; got != 9 {
+		t.Errorf("Want cursor 9, but got %d", got)
+	}
+
+	// Ctrl+E accepts the match and moves to the end of the line
+	feedAll(p, "\x05")
+	if p.search != nil {
+		t.Errorf("Want the history search to be stopped")
+	}
+	if got := p.buffer.cursorPosition; got != 12 {
+		t.Errorf("Want cursor 12, but got %d", got)
+	}
+	feedAll(p, "!")
+	if got := p.buffer.Text(); got != "echo foo bar!" {
+		t.Errorf("Want %q, but got %q", "echo foo bar!", got)
+	}
+}
+
+func TestHistorySearchLexer(t *testing.T) {
+	p := newTestPrompt(WithHistory([]string{"日本 foo"}))
+	feedAll(p, "\x12", "foo")
+
+	lexer := p.search.lexer(p.renderer)
+	lexer.Init(p.buffer.Text())
+	token, ok := lexer.Next()
+	if !ok {
+		t.Fatalf("Want a token")
+	}
+	if token.FirstByteIndex() != 7 || token.LastByteIndex() != 9 {
+		t.Errorf("Want token 7-9, but got %d-%d", token.FirstByteIndex(), token.LastByteIndex())
+	}
+	if want := istrings.RuneNumber(3); p.buffer.cursorPosition != want {
+		t.Errorf("Want cursor %d, but got %d", want, p.buffer.cursorPosition)
+	}
+	if _, ok := lexer.Next(); ok {
+		t.Errorf("Want a single token")
+	}
+}
@@ -0,0 +1,269 @@
+package prompt
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strings"
+	"time"
+)
+
+// HistoryEntry is a single entry of the history.
+type HistoryEntry struct {
+	Text       string    `json:"text"`
+	Timestamp  time.Time `json:"timestamp"`
+	WorkingDir string    `json:"working_dir,omitempty"`
+	SessionID  string    `json:"session_id,omitempty"`
+	// Exit status reported by the StatusExecutor,
+	// zero when the input hasn't been executed by one.
+	ExitStatus int `json:"exit_status,omitempty"`
+}
+
+// HistoryStore is a storage of the history entries.
+type HistoryStore interface {
+	// Add appends the entry to the store.
+	Add(entry HistoryEntry) error
+	// Iterate calls fn for every entry, the oldest one first,
+	// until fn returns false.
+	Iterate(fn func(entry HistoryEntry) bool) error
+	// Search returns the entries whose text contains the query,
+	// the oldest one first.
+	Search(query string) ([]HistoryEntry, error)
+	// Len returns the number of entries in the store.
+	Len() (int, error)
+}
+
+// HistoryExitStatusStore is a HistoryStore that can attach the exit status
+// to an entry after it has been added. Entries are added as soon as
+// the input is accepted, the exit status is only known once
+// the StatusExecutor returns.
+type HistoryExitStatusStore interface {
+	HistoryStore
+	// SetExitStatus sets the exit status of an entry added before.
+	SetExitStatus(entry HistoryEntry, exitStatus int) error
+}
+
+// Reports whether both values describe the same entry, ignoring the exit status.
+func sameHistoryEntry(a, b HistoryEntry) bool {
+	return a.Text == b.Text && a.SessionID == b.SessionID && a.Timestamp.Equal(b.Timestamp)
+}
+
+// Returns the entries of the store whose text contains the query.
+func searchHistoryStore(store HistoryStore, query string) ([]HistoryEntry, error) {
+	var entries []HistoryEntry
+	err := store.Iterate(func(entry HistoryEntry) bool {
+		if strings.Contains(entry.Text, query) {
+			entries = append(entries, entry)
+		}
+		return true
+	})
+	return entries, err
+}
+
+// MemoryHistoryStore is a HistoryStore that keeps the entries in memory.
+type MemoryHistoryStore struct {
+	entries []HistoryEntry
+}
+
+var _ HistoryExitStatusStore = &MemoryHistoryStore{}
+
+// NewMemoryHistoryStore returns a new in-memory store with the given entries.
+func NewMemoryHistoryStore(entries ...HistoryEntry) *MemoryHistoryStore {
+	return &MemoryHistoryStore{
+		entries: entries,
+	}
+}
+
+// Add appends the entry to the store.
+func (s *MemoryHistoryStore) Add(entry HistoryEntry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+// SetExitStatus sets the exit status of the most recent matching entry.
+func (s *MemoryHistoryStore) SetExitStatus(entry HistoryEntry, exitStatus int) error {
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		if sameHistoryEntry(s.entries[i], entry) {
+			s.entries[i].ExitStatus = exitStatus
+			break
+		}
+	}
+	return nil
+}
+
+// Iterate calls fn for every entry, the oldest one first,
+// until fn returns false.
+func (s *MemoryHistoryStore) Iterate(fn func(entry HistoryEntry) bool) error {
+	for _, entry := range s.entries {
+		if !fn(entry) {
+			break
+		}
+	}
+	return nil
+}
+
+// Search returns the entries whose text contains the query,
+// the oldest one first.
+func (s *MemoryHistoryStore) Search(query string) ([]HistoryEntry, error) {
+	return searchHistoryStore(s, query)
+}
+
+// Len returns the number of entries in the store.
+func (s *MemoryHistoryStore) Len() (int, error) {
+	return len(s.entries), nil
+}
+
+// JSONLinesHistoryStore is a HistoryStore that keeps the entries
+// with their metadata in a file, one JSON object per line.
+// Exit statuses set after an entry has been added are appended
+// as separate lines that get folded into the entries when the file
+// is trimmed, malformed lines are skipped.
+// Like HistoryFile, it can be shared by concurrent sessions.
+type JSONLinesHistoryStore struct {
+	config historyFileConfig
+}
+
+var _ HistoryExitStatusStore = &JSONLinesHistoryStore{}
+
+// Line of the file, either an entry or the exit status of an earlier one.
+type jsonHistoryLine struct {
+	HistoryEntry
+	StatusUpdate bool `json:"status_update,omitempty"`
+}
+
+// NewJSONLinesHistoryStore returns a new store that keeps the entries
+// in the file at the given path.
+// The file gets created when the first entry is added.
+func NewJSONLinesHistoryStore(path string, opts ...HistoryFileOption) *JSONLinesHistoryStore {
+	s := &JSONLinesHistoryStore{
+		config: newHistoryFileConfig(path, opts),
+	}
+	s.config.fold = foldJSONHistoryLines
+	return s
+}
+
+// Path returns the path of the file.
+func (s *JSONLinesHistoryStore) Path() string {
+	return s.config.path
+}
+
+// Add appends the entry to the end of the file
+// and trims the file when it exceeds its maximum size.
+func (s *JSONLinesHistoryStore) Add(entry HistoryEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.config.appendLine(string(line), true)
+}
+
+// SetExitStatus appends the exit status of the entry to the end of the file.
+func (s *JSONLinesHistoryStore) SetExitStatus(entry HistoryEntry, exitStatus int) error {
+	entry.ExitStatus = exitStatus
+	line, err := json.Marshal(jsonHistoryLine{HistoryEntry: entry, StatusUpdate: true})
+	if err != nil {
+		return err
+	}
+	return s.config.appendLine(string(line), false)
+}
+
+// Iterate calls fn for every entry, the oldest one first,
+// until fn returns false.
+func (s *JSONLinesHistoryStore) Iterate(fn func(entry HistoryEntry) bool) error {
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !fn(entry) {
+			break
+		}
+	}
+	return nil
+}
+
+// Search returns the entries whose text contains the query,
+// the oldest one first.
+func (s *JSONLinesHistoryStore) Search(query string) ([]HistoryEntry, error) {
+	return searchHistoryStore(s, query)
+}
+
+// Len returns the number of entries in the file.
+func (s *JSONLinesHistoryStore) Len() (int, error) {
+	entries, err := s.load()
+	return len(entries), err
+}
+
+func (s *JSONLinesHistoryStore) load() ([]HistoryEntry, error) {
+	lines, err := s.config.readLines()
+	if err != nil {
+		return nil, err
+	}
+	return parseJSONHistoryLines(lines), nil
+}
+
+// Returns the entries of the lines with the exit statuses
+// of the status lines merged into them.
+func parseJSONHistoryLines(lines []string) []HistoryEntry {
+	entries := make([]HistoryEntry, 0, len(lines))
+	for _, line := range lines {
+		var l jsonHistoryLine
+		// a line can be truncated when a session crashed while writing it
+		if err := json.Unmarshal([]byte(line), &l); err != nil {
+			continue
+		}
+		if !l.StatusUpdate {
+			entries = append(entries, l.HistoryEntry)
+			continue
+		}
+		for i := len(entries) - 1; i >= 0; i-- {
+			if sameHistoryEntry(entries[i], l.HistoryEntry) {
+				entries[i].ExitStatus = l.ExitStatus
+				break
+			}
+		}
+	}
+	return entries
+}
+
+// Folds the status lines into the lines of their entries
+// and drops the malformed ones, so that every line is an entry.
+func foldJSONHistoryLines(lines []string) []string {
+	entries := parseJSONHistoryLines(lines)
+	folded := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			continue
+		}
+		folded = append(folded, string(line))
+	}
+	return folded
+}
+
+// Returns a random identifier of the session.
+func newSessionID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// Returns a new history entry with the metadata of the current session.
+func (p *Prompt) newHistoryEntry(text string) HistoryEntry {
+	wd, _ := os.Getwd()
+	return HistoryEntry{
+		Text:       text,
+		Timestamp:  time.Now(),
+		WorkingDir: wd,
+		SessionID:  p.sessionID,
+	}
+}
+
+// SessionID returns the identifier of the session
+// that gets stored with the history entries.
+func (p *Prompt) SessionID() string {
+	return p.sessionID
+}
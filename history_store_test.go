@@ -0,0 +1,221 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMemoryHistoryStore(t *testing.T) {
+	s := NewMemoryHistoryStore(HistoryEntry{Text: "git status"})
+	if err := s.Add(HistoryEntry{Text: "ls"}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := s.Add(HistoryEntry{Text: "git commit"}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if n, err := s.Len(); err != nil || n != 3 {
+		t.Errorf("Want 3 entries, but got %d (%v)", n, err)
+	}
+
+	entries, err := s.Search("git")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if want := []HistoryEntry{{Text: "git status"}, {Text: "git commit"}}; !reflect.DeepEqual(want, entries) {
+		t.Errorf("Want %#v, but got %#v", want, entries)
+	}
+
+	var texts []string
+	err = s.Iterate(func(entry HistoryEntry) bool {
+		texts = append(texts, entry.Text)
+		return len(texts) < 2
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if want := []string{"git status", "ls"}; !reflect.DeepEqual(want, texts) {
+		t.Errorf("Want %#v, but got %#v", want, texts)
+	}
+}
+
+func TestJSONLinesHistoryStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	s := NewJSONLinesHistoryStore(path, WithHistoryFileMaxSize(2))
+
+	timestamp := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	added := []HistoryEntry{
+		{Text: "ls", Timestamp: timestamp},
+		{Text: "cat foo\nbar", Timestamp: timestamp, WorkingDir: "/tmp", SessionID: "abc", ExitStatus: 1},
+		{Text: "make test", Timestamp: timestamp, WorkingDir: "/src", SessionID: "abc", ExitStatus: 2},
+	}
+	for _, entry := range added {
+		if err := s.Add(entry); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	if n, err := s.Len(); err != nil || n != 2 {
+		t.Errorf("Want 2 entries, but got %d (%v)", n, err)
+	}
+	entries, err := s.Search("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if want := added[1:]; !reflect.DeepEqual(want, entries) {
+		t.Errorf("Want %#v, but got %#v", want, entries)
+	}
+}
+
+func TestJSONLinesHistoryStoreExitStatus(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	s := NewJSONLinesHistoryStore(path)
+
+	timestamp := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	entry := HistoryEntry{Text: "make", Timestamp: timestamp, SessionID: "abc"}
+	if err := s.Add(entry); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := s.Add(HistoryEntry{Text: "ls", Timestamp: timestamp, SessionID: "abc"}); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := s.SetExitStatus(entry, 2); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	entries, err := s.Search("")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	want := []HistoryEntry{
+		{Text: "make", Timestamp: timestamp, SessionID: "abc", ExitStatus: 2},
+		{Text: "ls", Timestamp: timestamp, SessionID: "abc"},
+	}
+	if !reflect.DeepEqual(want, entries) {
+		t.Errorf("Want %#v, but got %#v", want, entries)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if strings.Count(string(content), "exit_status") != 1 {
+		t.Errorf("Want only the status update to contain the exit status, but got %q", content)
+	}
+}
+
+func TestJSONLinesHistoryStoreExitStatusMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	s := NewJSONLinesHistoryStore(path, WithHistoryFileMaxSize(2))
+
+	timestamp := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	var want []HistoryEntry
+	for i, text := range []string{"ls", "pwd", "make", "git status"} {
+		entry := HistoryEntry{Text: text, Timestamp: timestamp, SessionID: "abc"}
+		if err := s.Add(entry); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if err := s.SetExitStatus(entry, i+1); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		entry.ExitStatus = i + 1
+		want = append(want, entry)
+
+		// status lines don't count toward the maximum size
+		entries, err := s.Search("")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if start := len(want) - 2; start > 0 {
+			want = want[start:]
+		}
+		if !reflect.DeepEqual(want, entries) {
+			t.Errorf("Want %#v, but got %#v", want, entries)
+		}
+	}
+
+	// the statuses of the kept entries are folded into them when the file gets trimmed
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if lines := strings.Count(string(content), "\n"); lines != 3 {
+		t.Errorf("Want 3 lines, but got %q", content)
+	}
+	if strings.Count(string(content), "status_update") != 1 {
+		t.Errorf("Want only the last status to be a separate line, but got %q", content)
+	}
+}
+
+func TestJSONLinesHistoryStoreSkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	content := `{"text":"ls","timestamp":"2023-01-02T03:04:05Z"}
+{"text":"git st
+not json
+{"text":"pwd","timestamp":"2023-01-02T03:04:05Z"}
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var texts []string
+	err := NewJSONLinesHistoryStore(path).Iterate(func(entry HistoryEntry) bool {
+		texts = append(texts, entry.Text)
+		return true
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if want := []string{"ls", "pwd"}; !reflect.DeepEqual(want, texts) {
+		t.Errorf("Want %#v, but got %#v", want, texts)
+	}
+}
+
+func TestHistoryStoreEntryMetadata(t *testing.T) {
+	store := NewMemoryHistoryStore(HistoryEntry{Text: "echo 1"})
+	p := newTestPrompt(
+		WithHistoryStore(store),
+		WithStatusExecutor(func(in string) int {
+			return len(in)
+		}),
+	)
+	if want := []string{"echo 1"}; !reflect.DeepEqual(want, p.history.histories) {
+		t.Errorf("Want %#v, but got %#v", want, p.history.histories)
+	}
+
+	// the entry is added before the input gets executed
+	input := feedAll(p, "false", "\n")
+	entries, err := store.Search("false")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("Want 1 entry, but got %#v", entries)
+	}
+	if entries[0].ExitStatus != 0 {
+		t.Errorf("Want exit status 0, but got %d", entries[0].ExitStatus)
+	}
+
+	p.history.setExitStatus(p.execute(input.input))
+	entries, err = store.Search("false")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	entry := entries[0]
+	if entry.ExitStatus != 5 {
+		t.Errorf("Want exit status 5, but got %d", entry.ExitStatus)
+	}
+	if entry.SessionID == "" || entry.SessionID != p.SessionID() {
+		t.Errorf("Want session ID %q, but got %q", p.SessionID(), entry.SessionID)
+	}
+	if entry.WorkingDir == "" {
+		t.Errorf("Want the working directory to be set")
+	}
+	if entry.Timestamp.IsZero() {
+		t.Errorf("Want the timestamp to be set")
+	}
+}
@@ -3,6 +3,8 @@ package prompt
 import (
 	"reflect"
 	"testing"
+
+	istrings "github.com/plandex-ai/go-prompt/strings"
 )
 
 func TestHistoryClear(t *testing.T) {
@@ -60,3 +62,106 @@ func TestHistoryOlder(t *testing.T) {
 		t.Errorf("Should be %#v, but got %#v", "echo 1", buf2.Text())
 	}
 }
+
+func TestHistoryControl(t *testing.T) {
+	tests := map[string]struct {
+		opts   []Option
+		inputs []string
+		want   []string
+	}{
+		"no policies": {
+			inputs: []string{"ls", "ls", " secret", "exit"},
+			want:   []string{"ls", "ls", " secret", "exit"},
+		},
+		"ignore consecutive duplicates": {
+			opts:   []Option{WithHistoryControl(HistoryIgnoreDups)},
+			inputs: []string{"ls", "ls", "pwd", "ls"},
+			want:   []string{"ls", "pwd", "ls"},
+		},
+		"erase older duplicates": {
+			opts:   []Option{WithHistoryControl(HistoryEraseDups)},
+			inputs: []string{"ls", "pwd", "ls", "cd", "pwd"},
+			want:   []string{"ls", "cd", "pwd"},
+		},
+		"ignore inputs starting with a space": {
+			opts:   []Option{WithHistoryControl(HistoryIgnoreBoth)},
+			inputs: []string{"ls", " export TOKEN=secret", "ls"},
+			want:   []string{"ls"},
+		},
+		"ignore inputs matching patterns": {
+			opts:   []Option{WithHistoryIgnore(`^exit$`, `^(ls|pwd)\b`)},
+			inputs: []string{"ls -la", "exit", "exit 1", "pwd", "lsof"},
+			want:   []string{"exit 1", "lsof"},
+		},
+		"limit the number of entries": {
+			opts:   []Option{WithHistoryMaxSize(2)},
+			inputs: []string{"a", "b", "c"},
+			want:   []string{"b", "c"},
+		},
+		"apply the policies to the loaded entries": {
+			opts: []Option{
+				WithHistory([]string{"a", "b", "a", "a", "c"}),
+				WithHistoryControl(HistoryEraseDups),
+				WithHistoryMaxSize(3),
+			},
+			inputs: []string{"b"},
+			want:   []string{"a", "c", "b"},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := newTestPrompt(tc.opts...)
+			for _, input := range tc.inputs {
+				p.history.Add(input)
+			}
+			if !reflect.DeepEqual(tc.want, p.history.histories) {
+				t.Errorf("Want %#v, but got %#v", tc.want, p.history.histories)
+			}
+			if want := append(append([]string{}, tc.want...), ""); !reflect.DeepEqual(want, p.history.tmp) {
+				t.Errorf("Want %#v, but got %#v", want, p.history.tmp)
+			}
+		})
+	}
+}
+
+func TestWithHistoryIgnoreInvalidPattern(t *testing.T) {
+	p := &Prompt{history: NewHistory()}
+	if err := WithHistoryIgnore(`(`)(p); err == nil {
+		t.Errorf("Want an error for an invalid pattern")
+	}
+}
+
+func TestHistoryPrefixSearch(t *testing.T) {
+	p := newTestPrompt(
+		WithHistory([]string{"git status", "ls", "git commit", "git status", "go test"}),
+		WithHistoryPrefixSearch(),
+	)
+	const up, down = "\x1b[A", "\x1b[B"
+	feedAll(p, "git ")
+
+	wantBuffer := func(text string, cursor istrings.RuneNumber) {
+		t.Helper()
+		if got := p.buffer.Text(); got != text {
+			t.Errorf("Want %q, but got %q", text, got)
+		}
+		if got := p.buffer.cursorPosition; got != cursor {
+			t.Errorf("Want cursor %d, but got %d", cursor, got)
+		}
+	}
+
+	feedAll(p, up)
+	wantBuffer("git status", 4)
+	feedAll(p, up)
+	wantBuffer("git commit", 4)
+	// the older "git status" is a duplicate
+	feedAll(p, up)
+	wantBuffer("git commit", 4)
+
+	feedAll(p, down)
+	wantBuffer("git status", 4)
+	feedAll(p, down)
+	wantBuffer("git ", 4)
+	feedAll(p, down)
+	wantBuffer("git ", 4)
+}
@@ -77,6 +77,7 @@ const (
 	Insert
 	Backspace
 	AltBackspace
+	AltY
 
 	// Aliases.
 	Tab
@@ -1,5 +1,7 @@
 package prompt
 
+import "time"
+
 // KeyBindFunc receives buffer and processed it.
 type KeyBindFunc func(p *Prompt) (rerender bool)
 
@@ -15,6 +17,17 @@ type ASCIICodeBind struct {
 	Fn        KeyBindFunc
 }
 
+// KeySequenceBind represents which sequence of keys
+// (like Ctrl+X Ctrl+E) should do what operation.
+type KeySequenceBind struct {
+	Keys []Key
+	Fn   KeyBindFunc
+}
+
+// DefaultKeySequenceTimeout is the default time the prompt waits
+// for the next key of an incomplete key sequence.
+const DefaultKeySequenceTimeout = time.Second
+
 // KeyBindMode to switch a key binding flexibly.
 type KeyBindMode uint8
 
@@ -23,6 +36,8 @@ const (
 	CommonKeyBind KeyBindMode = iota
 	// EmacsKeyBind is a mode to use emacs-like keyboard shortcut
 	EmacsKeyBind
+	// ViKeyBind is a mode to use vi-like modal keyboard shortcuts
+	ViKeyBind
 )
 
 var commonKeyBindings = []KeyBind{
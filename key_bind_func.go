@@ -46,3 +46,73 @@ func DeleteWordBeforeCursor(p *Prompt) bool {
 	)
 	return true
 }
+
+// KillLine Cut the line after the cursor to the kill ring
+func KillLine(p *Prompt) bool {
+	p.KillRunes(istrings.RuneCountInString(p.buffer.Document().CurrentLineAfterCursor()))
+	return true
+}
+
+// KillLineBeforeCursor Cut the line before the cursor to the kill ring
+func KillLineBeforeCursor(p *Prompt) bool {
+	p.KillBeforeCursorRunes(istrings.RuneCountInString(p.buffer.Document().CurrentLineBeforeCursor()))
+	return true
+}
+
+// KillWordBeforeCursor Cut the word before the cursor to the kill ring
+func KillWordBeforeCursor(p *Prompt) bool {
+	p.KillBeforeCursorRunes(istrings.RuneCountInString(p.buffer.Document().GetWordBeforeCursorWithSpace()))
+	return true
+}
+
+// Yank Paste the last thing to be cut
+func Yank(p *Prompt) bool {
+	text, ok := p.killRing.Latest()
+	if !ok {
+		return false
+	}
+	p.InsertTextMoveCursor(text, false)
+	// the following Alt+Y rotates from the latest entry again
+	p.killRing.yankIndex = p.killRing.Len() - 1
+	p.killRing.yankLen = istrings.RuneCountInString(text)
+	p.killRing.action = killRingActionYank
+	return true
+}
+
+// YankPop Replace the text that has just been yanked with an older entry of the kill ring
+func YankPop(p *Prompt) bool {
+	if p.killRing.lastAction != killRingActionYank {
+		return false
+	}
+	text, ok := p.killRing.Rotate()
+	if !ok {
+		return false
+	}
+	// replacing the yanked text is undone in a single step
+	p.buffer.beginEdit(editOther)
+	p.DeleteBeforeCursorRunes(p.killRing.yankLen)
+	p.InsertTextMoveCursor(text, false)
+	p.buffer.endEdit()
+	p.killRing.yankLen = istrings.RuneCountInString(text)
+	p.killRing.action = killRingActionYank
+	return true
+}
+
+// ToggleLineStart Toggle between the start of the line and the cursor position
+// it was moved from
+func ToggleLineStart(p *Prompt) bool {
+	d := p.buffer.Document()
+	before := istrings.RuneCountInString(d.CurrentLineBeforeCursor())
+	if before > 0 {
+		p.mark = p.buffer.cursorPosition
+		return p.CursorLeftRunes(before)
+	}
+	if p.mark <= p.buffer.cursorPosition {
+		return false
+	}
+	count := p.mark - p.buffer.cursorPosition
+	if after := istrings.RuneCountInString(d.CurrentLineAfterCursor()); count > after {
+		count = after
+	}
+	return p.CursorRightRunes(count)
+}
@@ -0,0 +1,157 @@
+package prompt
+
+import (
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// DefaultKillRingSize is the default maximum number of entries
+// stored in the kill ring.
+const DefaultKillRingSize = 60
+
+type killRingAction uint8
+
+const (
+	killRingActionNone killRingAction = iota
+	killRingActionKill
+	killRingActionYank
+)
+
+// KillRing stores the text removed by kill commands
+// (like Ctrl+K, Ctrl+U, Ctrl+W) so that it can be yanked back into the buffer.
+type KillRing struct {
+	entries   []string // oldest first
+	max       int
+	yankIndex int                 // index of the entry inserted by the last yank
+	yankLen   istrings.RuneNumber // length of the text inserted by the last yank
+
+	// actions performed during the previous and the current key press
+	lastAction killRingAction
+	action     killRingAction
+}
+
+// NewKillRing returns a new kill ring that keeps
+// at most max entries.
+func NewKillRing(max int) *KillRing {
+	return &KillRing{
+		max: max,
+	}
+}
+
+// Push adds a new entry to the kill ring,
+// discarding the oldest one when the ring is full.
+func (k *KillRing) Push(text string) {
+	k.entries = append(k.entries, text)
+	if k.max > 0 && len(k.entries) > k.max {
+		k.entries = k.entries[len(k.entries)-k.max:]
+	}
+	k.yankIndex = len(k.entries) - 1
+}
+
+// Append adds the text to the end of the most recent entry.
+func (k *KillRing) Append(text string) {
+	if len(k.entries) == 0 {
+		k.Push(text)
+		return
+	}
+	k.entries[len(k.entries)-1] += text
+	k.yankIndex = len(k.entries) - 1
+}
+
+// Prepend adds the text to the start of the most recent entry.
+func (k *KillRing) Prepend(text string) {
+	if len(k.entries) == 0 {
+		k.Push(text)
+		return
+	}
+	k.entries[len(k.entries)-1] = text + k.entries[len(k.entries)-1]
+	k.yankIndex = len(k.entries) - 1
+}
+
+// Latest returns the most recent entry.
+// The second value is false when the ring is empty.
+func (k *KillRing) Latest() (string, bool) {
+	if len(k.entries) == 0 {
+		return "", false
+	}
+	return k.entries[len(k.entries)-1], true
+}
+
+// Rotate moves to the previous (older) entry, wrapping around
+// to the newest one, and returns it.
+// The second value is false when the ring is empty.
+func (k *KillRing) Rotate() (string, bool) {
+	if len(k.entries) == 0 {
+		return "", false
+	}
+	k.yankIndex--
+	if k.yankIndex < 0 || k.yankIndex >= len(k.entries) {
+		k.yankIndex = len(k.entries) - 1
+	}
+	return k.entries[k.yankIndex], true
+}
+
+// Entries returns the entries of the kill ring, the most recent one first.
+func (k *KillRing) Entries() []string {
+	entries := make([]string, len(k.entries))
+	for i, entry := range k.entries {
+		entries[len(k.entries)-1-i] = entry
+	}
+	return entries
+}
+
+// Len returns the number of entries in the kill ring.
+func (k *KillRing) Len() int {
+	return len(k.entries)
+}
+
+// Clear removes all entries from the kill ring.
+func (k *KillRing) Clear() {
+	k.entries = nil
+	k.yankIndex = 0
+}
+
+// Should be called at the start of every key press
+// so that consecutive kills and yanks can be detected.
+func (k *KillRing) nextKey() {
+	k.lastAction = k.action
+	k.action = killRingActionNone
+}
+
+// Stores killed text. Consecutive kills are merged into a single entry,
+// text killed backwards is prepended to it.
+func (k *KillRing) kill(text string, backward bool) {
+	if text == "" {
+		return
+	}
+
+	switch {
+	case k.lastAction != killRingActionKill:
+		k.Push(text)
+	case backward:
+		k.Prepend(text)
+	default:
+		k.Append(text)
+	}
+	k.action = killRingActionKill
+}
+
+// KillRing returns the kill ring of the prompt.
+func (p *Prompt) KillRing() *KillRing {
+	return p.killRing
+}
+
+// Deletes the specified number of runes after the cursor,
+// stores them in the kill ring and returns the deleted text.
+func (p *Prompt) KillRunes(count istrings.RuneNumber) string {
+	deleted := p.DeleteRunes(count)
+	p.killRing.kill(deleted, false)
+	return deleted
+}
+
+// Deletes the specified number of runes before the cursor,
+// stores them in the kill ring and returns the deleted text.
+func (p *Prompt) KillBeforeCursorRunes(count istrings.RuneNumber) string {
+	deleted := p.DeleteBeforeCursorRunes(count)
+	p.killRing.kill(deleted, true)
+	return deleted
+}
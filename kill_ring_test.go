@@ -0,0 +1,97 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKillRing(t *testing.T) {
+	k := NewKillRing(2)
+	k.Push("foo")
+	k.Push("bar")
+	k.Append("!")
+	k.Prepend("<")
+	k.Push("baz")
+
+	if want, got := []string{"baz", "<bar!"}, k.Entries(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Want %#v, but got %#v", want, got)
+	}
+	if latest, ok := k.Latest(); !ok || latest != "baz" {
+		t.Errorf("Want %q, but got %q", "baz", latest)
+	}
+	if older, ok := k.Rotate(); !ok || older != "<bar!" {
+		t.Errorf("Want %q, but got %q", "<bar!", older)
+	}
+	if newest, ok := k.Rotate(); !ok || newest != "baz" {
+		t.Errorf("Want %q, but got %q", "baz", newest)
+	}
+}
+
+func TestKillAndYank(t *testing.T) {
+	p := newTestPrompt()
+	feedAll(p, "foo bar baz")
+
+	// consecutive kills are merged
+	feedAll(p, string([]byte{0x17}), string([]byte{0x17}))
+	if got := p.buffer.Text(); got != "foo " {
+		t.Errorf("Want %q, but got %q", "foo ", got)
+	}
+	if want, got := []string{"bar baz"}, p.KillRing().Entries(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Want %#v, but got %#v", want, got)
+	}
+
+	// a kill after another key starts a new entry
+	feedAll(p, string([]byte{0x1}), string([]byte{0xb}))
+	if want, got := []string{"foo ", "bar baz"}, p.KillRing().Entries(); !reflect.DeepEqual(want, got) {
+		t.Errorf("Want %#v, but got %#v", want, got)
+	}
+
+	// Ctrl+Y yanks the latest kill, Alt+Y replaces it with an older one
+	feedAll(p, string([]byte{0x19}))
+	if got := p.buffer.Text(); got != "foo " {
+		t.Errorf("Want %q, but got %q", "foo ", got)
+	}
+	feedAll(p, string([]byte{0x1b, 0x79}))
+	if got := p.buffer.Text(); got != "bar baz" {
+		t.Errorf("Want %q, but got %q", "bar baz", got)
+	}
+
+	// Alt+Y does nothing when the previous key didn't yank
+	feedAll(p, "!", string([]byte{0x1b, 0x79}))
+	if got := p.buffer.Text(); got != "bar baz!" {
+		t.Errorf("Want %q, but got %q", "bar baz!", got)
+	}
+}
+
+func TestYankPopAfterAnotherYank(t *testing.T) {
+	p := newTestPrompt()
+	p.KillRing().Push("a")
+	p.KillRing().Push("b")
+	p.KillRing().Push("c")
+
+	feedAll(p, string([]byte{0x19}), string([]byte{0x1b, 0x79}))
+	if got := p.buffer.Text(); got != "b" {
+		t.Errorf("Want %q, but got %q", "b", got)
+	}
+
+	// a new yank rotates from the latest entry again
+	feedAll(p, " ", string([]byte{0x19}), string([]byte{0x1b, 0x79}))
+	if got := p.buffer.Text(); got != "b b" {
+		t.Errorf("Want %q, but got %q", "b b", got)
+	}
+}
+
+func TestUndoYankPop(t *testing.T) {
+	p := newTestPrompt()
+	p.KillRing().Push("foo")
+	p.KillRing().Push("bar")
+
+	feedAll(p, string([]byte{0x19}), string([]byte{0x1b, 0x79}), string([]byte{0x1f}))
+	if got := p.buffer.Text(); got != "bar" {
+		t.Errorf("Want %q, but got %q", "bar", got)
+	}
+	feedAll(p, string([]byte{0x1f}))
+	if got := p.buffer.Text(); got != "" {
+		t.Errorf("Want %q, but got %q", "", got)
+	}
+}
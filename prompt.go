@@ -18,6 +18,11 @@ const inputBufferSize = 1024
 // inputs a line of text.
 type Executor func(string)
 
+// StatusExecutor is called when the user
+// inputs a line of text and returns its exit status
+// which gets stored in the history.
+type StatusExecutor func(string) (exitStatus int)
+
 // ExitChecker is called after user input to check if prompt must stop and exit go-prompt Run loop.
 // User input means: selecting/typing an entry, then, if said entry content matches the ExitChecker function criteria:
 // - immediate exit (if breakline is false) without executor called
@@ -46,17 +51,33 @@ type Prompt struct {
 	buffer                 *Buffer
 	renderer               *Renderer
 	executor               Executor
+	statusExecutor         StatusExecutor
+	sessionID              string
 	history                *History
 	lexer                  Lexer
 	completion             *CompletionManager
 	keyBindings            []KeyBind
 	ASCIICodeBindings      []ASCIICodeBind
 	keyBindMode            KeyBindMode
+	vi                     viState
+	killRing               *KillRing
+	keySequenceBindings    []KeySequenceBind
+	keySequenceTimeout     time.Duration
+	pendingKeys            [][]byte  // keys that may be a part of a key sequence
+	pendingKeysDeadline    time.Time // time after which the pending keys stop waiting for the rest of the sequence
+	mark                   istrings.RuneNumber
+	editorRequested        bool
+	search                 *historySearch // nil when the history isn't being searched
+	lastHistorySearchQuery string
+	historyExpansion       bool
+	autoSuggester          AutoSuggester
+	executeOnEditorSave    bool
 	completionOnDown       bool
 	exitChecker            ExitChecker
 	executeOnEnterCallback ExecuteOnEnterCallback
 	skipClose              bool
 	completionReset        bool
+	completionOriginal     string // text replaced by the selected suggestion, restored when the completion gets cancelled
 }
 
 // UserInput is the struct that contains the user input context.
@@ -76,63 +97,64 @@ func (p *Prompt) Run() {
 		p.completion.Update(*p.buffer.Document())
 	}
 
-	p.renderer.Render(p.buffer, p.completion, p.lexer)
+	p.render()
 
-	bufCh := make(chan []byte, 128)
-	stopReadBufCh := make(chan struct{})
-	go p.readBuffer(bufCh, stopReadBufCh)
+	loop := newInputLoop(true)
+	p.startInputLoop(loop)
 
-	exitCh := make(chan int)
-	winSizeCh := make(chan *WinSize)
-	stopHandleSignalCh := make(chan struct{})
-	go p.handleSignals(exitCh, winSizeCh, stopHandleSignalCh)
+	// handleFeedResult executes the accepted input
+	// and returns true when the Run loop should stop.
+	handleFeedResult := func(shouldExit, rerender bool, input *UserInput) (stop bool) {
+		stop, input = p.handleFeedResult(loop, shouldExit, rerender, input)
+		if !stop || input == nil {
+			return stop
+		}
 
-	for {
-		select {
-		case b := <-bufCh:
-			if shouldExit, rerender, input := p.feed(b); shouldExit {
-				p.renderer.BreakLine(p.buffer, p.lexer)
-				stopReadBufCh <- struct{}{}
-				stopHandleSignalCh <- struct{}{}
-				return
-			} else if input != nil {
-				// Stop goroutine to run readBuffer function
-				stopReadBufCh <- struct{}{}
-				stopHandleSignalCh <- struct{}{}
+		// Unset raw mode
+		// Reset to Blocking mode because returned EAGAIN when still set non-blocking mode.
+		debug.AssertNoError(p.reader.Close())
+		p.history.setExitStatus(p.execute(input.input))
 
-				// Unset raw mode
-				// Reset to Blocking mode because returned EAGAIN when still set non-blocking mode.
-				debug.AssertNoError(p.reader.Close())
-				p.executor(input.input)
+		p.completion.Update(*p.buffer.Document())
 
-				p.completion.Update(*p.buffer.Document())
+		p.render()
 
-				p.renderer.Render(p.buffer, p.completion, p.lexer)
+		if p.exitChecker != nil && p.exitChecker(input.input, true) {
+			p.skipClose = true
+			return true
+		}
+		// Set raw mode
+		debug.AssertNoError(p.reader.Open())
+		p.startInputLoop(loop)
+		return false
+	}
 
-				if p.exitChecker != nil && p.exitChecker(input.input, true) {
-					p.skipClose = true
-					return
-				}
-				// Set raw mode
-				debug.AssertNoError(p.reader.Open())
-				go p.readBuffer(bufCh, stopReadBufCh)
-				go p.handleSignals(exitCh, winSizeCh, stopHandleSignalCh)
-			} else if rerender {
-				if p.completion.shouldUpdate {
-					p.completion.Update(*p.buffer.Document())
-				}
-				p.renderer.Render(p.buffer, p.completion, p.lexer)
+	for {
+		select {
+		case b := <-loop.bufCh:
+			if handleFeedResult(p.feed(b)) {
+				return
 			}
-		case w := <-winSizeCh:
+		case r := <-p.completion.asyncResults():
+			if p.completion.handleAsyncCompletion(r) {
+				p.render()
+			}
+		case w := <-loop.winSizeCh:
 			p.renderer.UpdateWinSize(w)
 			p.buffer.resetStartLine()
 			p.buffer.recalculateStartLine(p.renderer.UserInputColumns(), int(p.renderer.row))
-			p.renderer.Render(p.buffer, p.completion, p.lexer)
-		case code := <-exitCh:
+			p.render()
+		case code := <-loop.exitCh:
 			p.renderer.BreakLine(p.buffer, p.lexer)
 			p.Close()
 			os.Exit(code)
 		default:
+			if p.keySequenceTimedOut() {
+				if handleFeedResult(p.flushPendingKeys()) {
+					return
+				}
+				continue
+			}
 			time.Sleep(10 * time.Millisecond)
 		}
 	}
@@ -173,9 +195,147 @@ func (p *Prompt) Buffer() *Buffer {
 	return p.buffer
 }
 
+// feed processes the input read from the terminal.
+// Keys that may start a key sequence are kept pending
+// until the sequence is complete, broken or timed out.
 func (p *Prompt) feed(b []byte) (shouldExit bool, rerender bool, userInput *UserInput) {
+	p.pendingKeys = append(p.pendingKeys, b)
+	return p.processPendingKeys(false)
+}
+
+// flushPendingKeys processes the pending keys
+// without waiting for the rest of the key sequence.
+func (p *Prompt) flushPendingKeys() (shouldExit bool, rerender bool, userInput *UserInput) {
+	return p.processPendingKeys(true)
+}
+
+func (p *Prompt) processPendingKeys(flush bool) (shouldExit bool, rerender bool, userInput *UserInput) {
+	for len(p.pendingKeys) > 0 {
+		keys := make([]Key, len(p.pendingKeys))
+		for i, b := range p.pendingKeys {
+			keys[i] = GetKey(b)
+		}
+
+		fn, isPrefix := p.matchKeySequence(keys)
+		if isPrefix && !flush {
+			p.pendingKeysDeadline = time.Now().Add(p.keySequenceTimeout)
+			return shouldExit, rerender, nil
+		}
+		if fn != nil {
+			p.pendingKeys = nil
+			p.buffer.lastKeyStroke = keys[len(keys)-1]
+			p.killRing.nextKey()
+			if fn(p) {
+				rerender = true
+			}
+			if p.exitChecker != nil && p.exitChecker(p.buffer.Text(), false) {
+				shouldExit = true
+			}
+			return shouldExit, rerender, nil
+		}
+
+		// no key sequence matches, so process the first key on its own
+		// and try again with the rest
+		b := p.pendingKeys[0]
+		p.pendingKeys = p.pendingKeys[1:]
+		var keyRerender bool
+		shouldExit, keyRerender, userInput = p.feedKey(b)
+		rerender = rerender || keyRerender
+		if shouldExit || userInput != nil {
+			p.pendingKeys = nil
+			return shouldExit, rerender, userInput
+		}
+	}
+
+	return shouldExit, rerender, userInput
+}
+
+// Renders the prompt, during the history search
+// the search prompt gets rendered instead of the prefix.
+func (p *Prompt) render() {
+	p.renderer.autoSuggestion = p.autoSuggestion()
+	p.renderer.selectionStart, p.renderer.selectionEnd = p.viSelection()
+	if p.search != nil {
+		p.renderer.renderHistorySearch(p.buffer, p.search.prompt(), p.search.lexer(p.renderer))
+		return
+	}
+	p.renderer.Render(p.buffer, p.completion, p.lexer)
+}
+
+// Breaks the line and starts a new buffer,
+// returns the text of the previous one as the user input.
+func (p *Prompt) acceptInput() *UserInput {
+	p.renderer.BreakLine(p.buffer, p.lexer)
+	userInput := &UserInput{input: p.buffer.Text()}
+	p.buffer = NewBuffer()
+	p.resetViState()
+	p.addHistory(userInput.input)
+	return userInput
+}
+
+// Runs the executor and returns the exit status of the input.
+func (p *Prompt) execute(input string) (exitStatus int) {
+	if p.statusExecutor != nil {
+		return p.statusExecutor(input)
+	}
+	p.executor(input)
+	return 0
+}
+
+// Adds the accepted input to the history.
+// It's added before the input gets executed
+// so that it isn't lost when the executor exits the process.
+func (p *Prompt) addHistory(input string) {
+	if input == "" {
+		return
+	}
+	p.history.AddEntry(p.newHistoryEntry(input))
+}
+
+// Returns the function bound to the given key sequence
+// and whether it's a prefix of a longer key sequence.
+func (p *Prompt) matchKeySequence(keys []Key) (fn KeyBindFunc, isPrefix bool) {
+	match := func(bindings []KeySequenceBind) {
+	bindingLoop:
+		for _, kb := range bindings {
+			if len(kb.Keys) < len(keys) {
+				continue
+			}
+			for i, key := range keys {
+				if kb.Keys[i] != key {
+					continue bindingLoop
+				}
+			}
+			if len(kb.Keys) == len(keys) {
+				fn = kb.Fn
+			} else {
+				isPrefix = true
+			}
+		}
+	}
+
+	switch p.keyBindMode {
+	case EmacsKeyBind:
+		match(emacsKeySequenceBindings)
+	}
+	// custom key sequences override the default ones
+	match(p.keySequenceBindings)
+	return fn, isPrefix
+}
+
+// keySequenceTimedOut returns true when the pending keys
+// have been waiting for the rest of a key sequence for too long.
+func (p *Prompt) keySequenceTimedOut() bool {
+	return len(p.pendingKeys) > 0 &&
+		p.keySequenceTimeout > 0 &&
+		time.Now().After(p.pendingKeysDeadline)
+}
+
+// feedKey processes a single key.
+func (p *Prompt) feedKey(b []byte) (shouldExit bool, rerender bool, userInput *UserInput) {
 	key := GetKey(b)
 	p.buffer.lastKeyStroke = key
+	p.killRing.nextKey()
 
 	// Reset history navigation when user types any character
 	// (except up/down arrows which are handled separately)
@@ -183,6 +343,14 @@ func (p *Prompt) feed(b []byte) (shouldExit bool, rerender bool, userInput *User
 		p.history.ResetNavigation()
 	}
 
+	if p.search != nil && p.handleHistorySearchKey(b, key) {
+		return false, true, nil
+	}
+
+	if p.keyBindMode == ViKeyBind && p.handleViKey(b, key) {
+		return false, true, nil
+	}
+
 	// completion
 	completing := p.completion.Completing()
 
@@ -191,6 +359,10 @@ func (p *Prompt) feed(b []byte) (shouldExit bool, rerender bool, userInput *User
 		return false, true, nil
 	}
 
+	if p.handleAutoSuggestionKey(key) {
+		return false, true, nil
+	}
+
 	cols := p.renderer.UserInputColumns()
 	rows := p.renderer.row
 
@@ -209,16 +381,15 @@ func (p *Prompt) feed(b []byte) (shouldExit bool, rerender bool, userInput *User
 			break
 		}
 
-		p.renderer.BreakLine(p.buffer, p.lexer)
-		userInput = &UserInput{input: p.buffer.Text()}
-		p.buffer = NewBuffer()
-		if userInput.input != "" {
-			p.history.Add(userInput.input)
+		if p.historyExpansion && !p.expandHistoryOnEnter() {
+			return false, true, nil
 		}
+		userInput = p.acceptInput()
 	case ControlC:
 		p.renderer.BreakLine(p.buffer, p.lexer)
 		p.buffer = NewBuffer()
 		p.history.Clear()
+		p.resetViState()
 	case Up, ControlP:
 		line := p.buffer.Document().CursorPositionRow()
 		if line > 0 {
@@ -265,7 +436,8 @@ func (p *Prompt) feed(b []byte) (shouldExit bool, rerender bool, userInput *User
 			return false, false, nil
 		}
 
-		p.buffer.InsertTextMoveCursor(string(b), cols, rows, false)
+		overwrite := p.keyBindMode == ViKeyBind && p.vi.mode == ViReplace
+		p.buffer.InsertTextMoveCursor(string(b), cols, rows, overwrite)
 	}
 
 	shouldExit, rerender = p.handleKeyBinding(key, cols, rows)
@@ -284,6 +456,16 @@ func (p *Prompt) handleCompletionKeyBinding(b []byte, key Key, completing bool)
 	completionLen := len(p.completion.tmp)
 	p.completionReset = false
 
+	if p.completion.layout == CompletionLayoutGrid {
+		_, p.completion.columns = formatGrid(p.completion.tmp, p.renderer.completionGridWidth(), p.renderer.suggestKindIcons)
+	}
+	if completing && p.handleCompletionMenuKey(key) {
+		return true
+	}
+	if p.completion.layout == CompletionLayoutGrid && completing && p.handleCompletionGridKey(key) {
+		return true
+	}
+
 keySwitch:
 	switch key {
 	case Down:
@@ -306,6 +488,9 @@ keySwitch:
 			return true
 		}
 	case Tab:
+		if !completing && p.completion.behavior == CompletionBehaviorCommonPrefix && p.insertCommonPrefix() {
+			return true
+		}
 		if completionLen > 0 {
 			// If there are any suggestions, select the next one
 			p.updateSuggestions(func() {
@@ -362,12 +547,44 @@ keySwitch:
 	return false
 }
 
+// Handles the keys that only work while a suggestion is selected.
+// Returns true when the key has been handled.
+func (p *Prompt) handleCompletionMenuKey(key Key) bool {
+	switch key {
+	case PageDown:
+		p.updateSuggestions(p.completion.NextPage)
+	case PageUp:
+		p.updateSuggestions(p.completion.PreviousPage)
+	case Home:
+		p.updateSuggestions(p.completion.First)
+	case End:
+		p.updateSuggestions(p.completion.Last)
+	case Escape, ControlG:
+		// restore the text that was there before the completion started,
+		// in the vi mode Escape leaves the insert mode instead
+		p.updateSuggestions(func() {
+			p.completion.selected = -1
+			p.completion.update()
+		})
+		p.completion.Reset()
+		p.completionReset = true
+	case Enter, ControlM, ControlJ:
+		// accept the selected suggestion without executing the input
+		p.completion.Reset()
+		p.completionReset = true
+	default:
+		return false
+	}
+	return true
+}
+
 func (p *Prompt) updateSuggestions(fn func()) {
 	cols := p.renderer.UserInputColumns()
 	rows := p.renderer.row
+	// cycling through the suggestions can be undone in a single step
+	p.buffer.beginEdit(editCompletion)
+	defer p.buffer.endEdit()
 
-	prevStart := p.completion.startCharIndex
-	prevEnd := p.completion.endCharIndex
 	prevSuggestion, prevSelected := p.completion.GetSelectedSuggestion()
 
 	fn()
@@ -380,30 +597,73 @@ func (p *Prompt) updateSuggestions(fn func()) {
 		return
 	}
 
+	// move the cursor back to the end of the previous selection
+	if prevSelected {
+		p.moveCursorRunes(-prevSuggestion.CursorOffset, cols, rows)
+	}
+
 	// insert the new selection
 	if !prevSelected {
-		p.buffer.DeleteBeforeCursorRunes(p.completion.endCharIndex-p.completion.startCharIndex, cols, rows)
-		p.buffer.InsertTextMoveCursor(newSuggestion.Text, cols, rows, false)
+		p.completionOriginal = p.buffer.DeleteBeforeCursorRunes(p.completion.endCharIndex-p.completion.startCharIndex, cols, rows)
+		p.buffer.InsertTextMoveCursor(newSuggestion.InsertedText(), cols, rows, false)
+		p.moveCursorRunes(newSuggestion.CursorOffset, cols, rows)
 		return
 	}
-	// delete the previous selection
+	// replace the previous selection with the original text
 	if !newSelected {
 		p.buffer.DeleteBeforeCursorRunes(
-			istrings.RuneCountInString(prevSuggestion.Text)-(prevEnd-prevStart),
+			istrings.RuneCountInString(prevSuggestion.InsertedText()),
 			cols,
 			rows,
 		)
+		p.buffer.InsertTextMoveCursor(p.completionOriginal, cols, rows, false)
+		p.completionOriginal = ""
 		return
 	}
 
 	// delete previous selection and render the new one
 	p.buffer.DeleteBeforeCursorRunes(
-		istrings.RuneCountInString(prevSuggestion.Text),
+		istrings.RuneCountInString(prevSuggestion.InsertedText()),
 		cols,
 		rows,
 	)
 
-	p.buffer.InsertTextMoveCursor(newSuggestion.Text, cols, rows, false)
+	p.buffer.InsertTextMoveCursor(newSuggestion.InsertedText(), cols, rows, false)
+	p.moveCursorRunes(newSuggestion.CursorOffset, cols, rows)
+}
+
+// Moves the cursor by the given number of runes,
+// to the left when it is negative.
+func (p *Prompt) moveCursorRunes(count istrings.RuneNumber, cols istrings.Width, rows int) {
+	switch {
+	case count < 0:
+		p.buffer.CursorLeftRunes(-count, cols, rows)
+	case count > 0:
+		p.buffer.CursorRightRunes(count, cols, rows)
+	}
+}
+
+// Replaces the text completed by the suggestions with their longest common prefix.
+// Returns false when the prefix doesn't extend the text.
+func (p *Prompt) insertCommonPrefix() bool {
+	prefix := []rune(p.completion.CommonPrefix())
+	text := []rune(p.buffer.Text())
+	start, end := p.completion.startCharIndex, p.completion.endCharIndex
+	if start < 0 || start > end || int(end) > len(text) || end != p.buffer.cursorPosition {
+		return false
+	}
+	word := text[start:end]
+	if len(prefix) <= len(word) || !strings.EqualFold(string(prefix[:len(word)]), string(word)) {
+		return false
+	}
+
+	cols := p.renderer.UserInputColumns()
+	rows := p.renderer.row
+	p.buffer.beginEdit(editOther)
+	defer p.buffer.endEdit()
+	p.buffer.DeleteBeforeCursorRunes(end-start, cols, rows)
+	p.buffer.InsertTextMoveCursor(string(prefix), cols, rows, false)
+	return true
 }
 
 func (p *Prompt) handleKeyBinding(key Key, cols istrings.Width, rows int) (shouldExit bool, rerender bool) {
@@ -431,6 +691,20 @@ func (p *Prompt) handleKeyBinding(key Key, cols istrings.Width, rows int) (shoul
 				}
 			}
 		}
+	case ViKeyBind:
+		if p.vi.mode != ViInsert && p.vi.mode != ViReplace {
+			break
+		}
+		for i := range viInsertKeyBindings {
+			kb := viInsertKeyBindings[i]
+			if kb.Key == key {
+				result := kb.Fn(p)
+				executed = true
+				if !rerender {
+					rerender = result
+				}
+			}
+		}
 	}
 
 	// Custom key bindings
@@ -478,32 +752,111 @@ func (p *Prompt) Input() string {
 		p.completion.Update(*p.buffer.Document())
 	}
 
-	p.renderer.Render(p.buffer, p.completion, p.lexer)
-	bufCh := make(chan []byte, 128)
-	stopReadBufCh := make(chan struct{})
-	go p.readBuffer(bufCh, stopReadBufCh)
+	p.render()
+	loop := newInputLoop(false)
+	p.startInputLoop(loop)
+
+	// handleFeedResult returns true and the accepted input
+	// (empty on exit) when the Input loop should stop.
+	handleFeedResult := func(shouldExit, rerender bool, input *UserInput) (stop bool, result string) {
+		stop, input = p.handleFeedResult(loop, shouldExit, rerender, input)
+		if input != nil {
+			result = input.input
+		}
+		return stop, result
+	}
 
 	for {
 		select {
-		case b := <-bufCh:
-			if shouldExit, rerender, input := p.feed(b); shouldExit {
-				p.renderer.BreakLine(p.buffer, p.lexer)
-				stopReadBufCh <- struct{}{}
-				return ""
-			} else if input != nil {
-				// Stop goroutine to run readBuffer function
-				stopReadBufCh <- struct{}{}
-				return input.input
-			} else if rerender {
-				p.completion.Update(*p.buffer.Document())
-				p.renderer.Render(p.buffer, p.completion, p.lexer)
+		case b := <-loop.bufCh:
+			if stop, result := handleFeedResult(p.feed(b)); stop {
+				return result
+			}
+		case r := <-p.completion.asyncResults():
+			if p.completion.handleAsyncCompletion(r) {
+				p.render()
 			}
 		default:
+			if p.keySequenceTimedOut() {
+				if stop, result := handleFeedResult(p.flushPendingKeys()); stop {
+					return result
+				}
+				continue
+			}
 			time.Sleep(10 * time.Millisecond)
 		}
 	}
 }
 
+// inputLoop holds the channels of the goroutines that read the terminal
+// (and handle the signals) while the prompt waits for the user.
+type inputLoop struct {
+	bufCh         chan []byte
+	stopReadBufCh chan struct{}
+	// set only when the signals are handled
+	exitCh             chan int
+	winSizeCh          chan *WinSize
+	stopHandleSignalCh chan struct{}
+}
+
+func newInputLoop(handleSignals bool) *inputLoop {
+	l := &inputLoop{
+		bufCh:         make(chan []byte, 128),
+		stopReadBufCh: make(chan struct{}),
+	}
+	if handleSignals {
+		l.exitCh = make(chan int)
+		l.winSizeCh = make(chan *WinSize)
+		l.stopHandleSignalCh = make(chan struct{})
+	}
+	return l
+}
+
+func (p *Prompt) startInputLoop(l *inputLoop) {
+	go p.readBuffer(l.bufCh, l.stopReadBufCh)
+	if l.stopHandleSignalCh != nil {
+		go p.handleSignals(l.exitCh, l.winSizeCh, l.stopHandleSignalCh)
+	}
+}
+
+func (p *Prompt) stopInputLoop(l *inputLoop) {
+	l.stopReadBufCh <- struct{}{}
+	if l.stopHandleSignalCh != nil {
+		l.stopHandleSignalCh <- struct{}{}
+	}
+}
+
+// handleFeedResult processes the outcome of the fed input,
+// running the editor when it has been requested.
+// It returns true when the loop should stop because the input
+// has been accepted or the prompt should exit (with a nil input),
+// in which case the goroutines of the loop are stopped.
+func (p *Prompt) handleFeedResult(l *inputLoop, shouldExit, rerender bool, input *UserInput) (stop bool, _ *UserInput) {
+	if p.editorRequested && !shouldExit && input == nil {
+		p.stopInputLoop(l)
+		debug.AssertNoError(p.reader.Close())
+		input = p.runEditor()
+		debug.AssertNoError(p.reader.Open())
+		p.startInputLoop(l)
+		rerender = true
+	}
+	if shouldExit {
+		p.renderer.BreakLine(p.buffer, p.lexer)
+		p.stopInputLoop(l)
+		return true, nil
+	} else if input != nil {
+		// Stop goroutine to run readBuffer function
+		p.stopInputLoop(l)
+		return true, input
+	} else if rerender {
+		if p.completion.shouldUpdate {
+			p.completion.Update(*p.buffer.Document())
+		}
+		p.render()
+	}
+	return false, nil
+}
+
 const IndentUnit = ' '
 const IndentUnitString = string(IndentUnit)
 
@@ -661,6 +1014,18 @@ func (p *Prompt) DeleteRunes(count istrings.RuneNumber) string {
 	return p.buffer.DeleteRunes(count, p.UserInputColumns(), p.renderer.row)
 }
 
+// Undo reverts the last edit of the buffer.
+// Returns true when the view should be rerendered.
+func (p *Prompt) Undo() bool {
+	return p.buffer.Undo(p.UserInputColumns(), p.renderer.row)
+}
+
+// Redo reapplies the last edit reverted by Undo.
+// Returns true when the view should be rerendered.
+func (p *Prompt) Redo() bool {
+	return p.buffer.Redo(p.UserInputColumns(), p.renderer.row)
+}
+
 // Insert string into the buffer without moving the cursor.
 func (p *Prompt) InsertText(text string, overwrite bool) {
 	p.buffer.InsertText(text, overwrite)
@@ -672,6 +1037,9 @@ func (p *Prompt) InsertTextMoveCursor(text string, overwrite bool) {
 }
 
 func (p *Prompt) Close() {
+	if p.completion.async != nil {
+		p.completion.cancelAsync()
+	}
 	if !p.skipClose {
 		debug.AssertNoError(p.reader.Close())
 	}
@@ -0,0 +1,164 @@
+package prompt
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// Writer that discards everything that gets flushed.
+type testWriter struct {
+	VT100Writer
+}
+
+func (w *testWriter) Flush() error {
+	w.buffer = w.buffer[:0]
+	return nil
+}
+
+// Reader that returns the given inputs one by one,
+// leaving the prompt the given delay to process each of them.
+type testReader struct {
+	inputs [][]byte
+	delay  time.Duration
+	next   time.Time
+}
+
+func (r *testReader) Open() error  { return nil }
+func (r *testReader) Close() error { return nil }
+
+func (r *testReader) GetWinSize() *WinSize {
+	return &WinSize{Row: DefRowCount, Col: DefColCount}
+}
+
+func (r *testReader) Read(b []byte) (int, error) {
+	if len(r.inputs) == 0 || time.Now().Before(r.next) {
+		return 0, io.EOF
+	}
+	n := copy(b, r.inputs[0])
+	r.inputs = r.inputs[1:]
+	r.next = time.Now().Add(r.delay)
+	return n, nil
+}
+
+// Returns a prompt that renders to a discarded output
+// with a terminal of the default size.
+func newTestPrompt(opts ...Option) *Prompt {
+	opts = append([]Option{WithWriter(&testWriter{})}, opts...)
+	p := New(NoopExecutor, opts...)
+	p.renderer.UpdateWinSize(&WinSize{Row: DefRowCount, Col: DefColCount})
+	return p
+}
+
+// Feeds every given input to the prompt as if it was read separately from the terminal.
+func feedAll(p *Prompt, inputs ...string) (userInput *UserInput) {
+	for _, in := range inputs {
+		if _, _, input := p.feed([]byte(in)); input != nil {
+			userInput = input
+		}
+	}
+	return userInput
+}
+
+func TestPromptFeedInsertsText(t *testing.T) {
+	p := newTestPrompt()
+	feedAll(p, "foo", " ", "bar")
+	if p.buffer.Text() != "foo bar" {
+		t.Errorf("Want %q, but got %q", "foo bar", p.buffer.Text())
+	}
+
+	input := feedAll(p, "\n")
+	if input == nil || input.input != "foo bar" {
+		t.Errorf("Want user input %q, but got %#v", "foo bar", input)
+	}
+	if p.buffer.Text() != "" {
+		t.Errorf("Want an empty buffer, but got %q", p.buffer.Text())
+	}
+}
+
+func TestPromptUndoCompletion(t *testing.T) {
+	p := newTestPrompt(WithCompleter(func(d Document) ([]Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+		word := d.GetWordBeforeCursor()
+		end := d.CurrentRuneIndex()
+		return FilterHasPrefix([]Suggest{{Text: "foobar"}, {Text: "foobaz"}}, word, false), end - istrings.RuneCountInString(word), end
+	}))
+	feedAll(p, "f", "o")
+	p.completion.Update(*p.buffer.Document())
+	feedAll(p, "\t", "\t")
+	if got := p.buffer.Text(); got != "foobaz" {
+		t.Errorf("Want %q, but got %q", "foobaz", got)
+	}
+
+	// cycling through the suggestions is undone in a single step
+	feedAll(p, string([]byte{0x1f}))
+	if got := p.buffer.Text(); got != "fo" {
+		t.Errorf("Want %q, but got %q", "fo", got)
+	}
+	feedAll(p, string([]byte{0x1f}))
+	if got := p.buffer.Text(); got != "" {
+		t.Errorf("Want %q, but got %q", "", got)
+	}
+}
+
+func TestPromptKeySequenceBind(t *testing.T) {
+	var called int
+	p := newTestPrompt(WithKeySequenceBind(KeySequenceBind{
+		Keys: []Key{ControlX, ControlE},
+		Fn: func(p *Prompt) bool {
+			called++
+			return true
+		},
+	}))
+	feedAll(p, "foo")
+
+	// the first key of the sequence waits for the rest
+	feedAll(p, string([]byte{0x18}))
+	if len(p.pendingKeys) != 1 {
+		t.Fatalf("Want 1 pending key, but got %d", len(p.pendingKeys))
+	}
+	feedAll(p, string([]byte{0x5}))
+	if called != 1 {
+		t.Errorf("Want the key sequence to be called once, but got %d", called)
+	}
+	if len(p.pendingKeys) != 0 {
+		t.Errorf("Want no pending keys, but got %d", len(p.pendingKeys))
+	}
+
+	// a broken sequence processes its keys on their own
+	feedAll(p, string([]byte{0x18}), string([]byte{0x1}))
+	if called != 1 {
+		t.Errorf("Want the key sequence to be called once, but got %d", called)
+	}
+	if got := p.buffer.cursorPosition; got != 0 {
+		t.Errorf("Want cursor 0, but got %d", got)
+	}
+
+	// an incomplete sequence is processed after the timeout
+	feedAll(p, string([]byte{0x18}))
+	if p.keySequenceTimedOut() {
+		t.Errorf("Want the key sequence not to be timed out yet")
+	}
+	p.pendingKeysDeadline = p.pendingKeysDeadline.Add(-2 * DefaultKeySequenceTimeout)
+	if !p.keySequenceTimedOut() {
+		t.Errorf("Want the key sequence to be timed out")
+	}
+	p.flushPendingKeys()
+	if len(p.pendingKeys) != 0 {
+		t.Errorf("Want no pending keys, but got %d", len(p.pendingKeys))
+	}
+}
+
+func TestEmacsToggleLineStart(t *testing.T) {
+	p := newTestPrompt()
+	feedAll(p, "foo bar", string([]byte{0x2}))
+	feedAll(p, string([]byte{0x18}), string([]byte{0x18}))
+	if got := p.buffer.cursorPosition; got != 0 {
+		t.Errorf("Want cursor 0, but got %d", got)
+	}
+	feedAll(p, string([]byte{0x18}), string([]byte{0x18}))
+	if got := p.buffer.cursorPosition; got != 6 {
+		t.Errorf("Want cursor 6, but got %d", got)
+	}
+}
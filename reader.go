@@ -178,4 +178,5 @@ var ASCIISequences = []*ASCIICode{
 	// MacOS Keybind
 	{Key: AltRight, ASCIICode: []byte{0x1b, 0x66}},
 	{Key: AltLeft, ASCIICode: []byte{0x1b, 0x62}},
+	{Key: AltY, ASCIICode: []byte{0x1b, 0x79}},
 }
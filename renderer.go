@@ -4,6 +4,7 @@ import (
 	"strings"
 	"unicode/utf8"
 
+	runewidth "github.com/mattn/go-runewidth"
 	"github.com/plandex-ai/go-prompt/debug"
 	istrings "github.com/plandex-ai/go-prompt/strings"
 )
@@ -21,22 +22,34 @@ type Renderer struct {
 	indentSize        int // How many spaces constitute a single indentation level
 
 	previousCursor Position
+	autoSuggestion string // text displayed after the input that is not a part of it
+	// runes of the input in the [selectionStart, selectionEnd) range are displayed in reverse video
+	selectionStart istrings.RuneNumber
+	selectionEnd   istrings.RuneNumber
 
 	// colors,
-	prefixTextColor              Color
-	prefixBGColor                Color
-	inputTextColor               Color
-	inputBGColor                 Color
-	suggestionTextColor          Color
-	suggestionBGColor            Color
-	selectedSuggestionTextColor  Color
-	selectedSuggestionBGColor    Color
-	descriptionTextColor         Color
-	descriptionBGColor           Color
-	selectedDescriptionTextColor Color
-	selectedDescriptionBGColor   Color
-	scrollbarThumbColor          Color
-	scrollbarBGColor             Color
+	prefixTextColor                  Color
+	prefixBGColor                    Color
+	inputTextColor                   Color
+	inputBGColor                     Color
+	suggestionTextColor              Color
+	suggestionBGColor                Color
+	selectedSuggestionTextColor      Color
+	selectedSuggestionBGColor        Color
+	suggestionMatchTextColor         Color
+	selectedSuggestionMatchTextColor Color
+	suggestKindIcons                 map[SuggestKind]string
+	groupHeaderTextColor             Color
+	groupHeaderBGColor               Color
+	descriptionTextColor             Color
+	descriptionBGColor               Color
+	selectedDescriptionTextColor     Color
+	selectedDescriptionBGColor       Color
+	scrollbarThumbColor              Color
+	scrollbarBGColor                 Color
+	autoSuggestionTextColor          Color
+	historySearchMatchTextColor      Color
+	historySearchMatchBGColor        Color
 }
 
 // Build a new Renderer.
@@ -45,23 +58,31 @@ func NewRenderer() *Renderer {
 	registerWriter(defaultWriter)
 
 	return &Renderer{
-		out:                          defaultWriter,
-		indentSize:                   DefaultIndentSize,
-		prefixCallback:               DefaultPrefixCallback,
-		prefixTextColor:              Blue,
-		prefixBGColor:                DefaultColor,
-		inputTextColor:               DefaultColor,
-		inputBGColor:                 DefaultColor,
-		suggestionTextColor:          White,
-		suggestionBGColor:            Cyan,
-		selectedSuggestionTextColor:  Black,
-		selectedSuggestionBGColor:    Turquoise,
-		descriptionTextColor:         Black,
-		descriptionBGColor:           Turquoise,
-		selectedDescriptionTextColor: White,
-		selectedDescriptionBGColor:   Cyan,
-		scrollbarThumbColor:          DarkGray,
-		scrollbarBGColor:             Cyan,
+		out:                              defaultWriter,
+		indentSize:                       DefaultIndentSize,
+		prefixCallback:                   DefaultPrefixCallback,
+		prefixTextColor:                  Blue,
+		prefixBGColor:                    DefaultColor,
+		inputTextColor:                   DefaultColor,
+		inputBGColor:                     DefaultColor,
+		suggestionTextColor:              White,
+		suggestionBGColor:                Cyan,
+		selectedSuggestionTextColor:      Black,
+		selectedSuggestionBGColor:        Turquoise,
+		suggestionMatchTextColor:         Black,
+		selectedSuggestionMatchTextColor: DarkRed,
+		suggestKindIcons:                 defaultSuggestKindIcons(),
+		groupHeaderTextColor:             White,
+		groupHeaderBGColor:               DarkGray,
+		descriptionTextColor:             Black,
+		descriptionBGColor:               Turquoise,
+		selectedDescriptionTextColor:     White,
+		selectedDescriptionBGColor:       Cyan,
+		scrollbarThumbColor:              DarkGray,
+		scrollbarBGColor:                 Cyan,
+		autoSuggestionTextColor:          DarkGray,
+		historySearchMatchTextColor:      Black,
+		historySearchMatchBGColor:        Yellow,
 	}
 }
 
@@ -108,23 +129,42 @@ func (r *Renderer) UpdateWinSize(ws *WinSize) {
 
 func (r *Renderer) renderCompletion(buf *Buffer, completions *CompletionManager) {
 	suggestions := completions.GetSuggestions()
+	query := completions.query
+	if len(suggestions) == 0 && completions.Loading() {
+		suggestions = []Suggest{{Text: completions.asyncLoadingText()}}
+		query = ""
+	}
 	if len(suggestions) == 0 {
 		return
 	}
+	if completions.layout == CompletionLayoutGrid {
+		r.renderCompletionGrid(buf, completions, suggestions, query)
+		return
+	}
 	prefix := r.prefixCallback()
 	prefixWidth := istrings.GetWidth(prefix)
 	formatted, width := formatSuggestions(
 		suggestions,
 		r.col-istrings.GetWidth(prefix)-1, // -1 means a width of scrollbar
+		r.suggestKindIcons,
 	)
+	icons := suggestionIconCells(suggestions, r.suggestKindIcons)
+	if width == 0 {
+		return
+	}
+	rows := completionRows(suggestions)
 	// +1 means a width of scrollbar.
 	width++
 
-	windowHeight := len(formatted)
+	windowHeight := len(rows)
 	if windowHeight > int(completions.max) {
 		windowHeight = int(completions.max)
 	}
-	formatted = formatted[completions.verticalScroll : completions.verticalScroll+windowHeight]
+	verticalScroll := completions.verticalScroll
+	if verticalScroll > len(rows)-windowHeight {
+		verticalScroll = len(rows) - windowHeight
+	}
+	rows = rows[verticalScroll : verticalScroll+windowHeight]
 	r.prepareArea(windowHeight)
 
 	cursor := positionAtEndOfString(buf.Document().TextBeforeCursor(), r.col-prefixWidth)
@@ -134,10 +174,10 @@ func (r *Renderer) renderCompletion(buf *Buffer, completions *CompletionManager)
 		cursor = r.backward(cursor, x+width-r.col)
 	}
 
-	contentHeight := len(completions.tmp)
+	contentHeight := len(completionRows(suggestions))
 
 	fractionVisible := float64(windowHeight) / float64(contentHeight)
-	fractionAbove := float64(completions.verticalScroll) / float64(contentHeight)
+	fractionAbove := float64(verticalScroll) / float64(contentHeight)
 
 	scrollbarHeight := int(clamp(float64(windowHeight), 1, float64(windowHeight)*fractionVisible))
 	scrollbarTop := int(float64(windowHeight) * fractionAbove)
@@ -146,29 +186,26 @@ func (r *Renderer) renderCompletion(buf *Buffer, completions *CompletionManager)
 		return scrollbarTop <= row && row <= scrollbarTop+scrollbarHeight
 	}
 
-	selected := completions.selected - completions.verticalScroll
 	cursorColumnSpacing := cursor
 
 	r.out.SetColor(White, Cyan, false)
-	for i := 0; i < windowHeight; i++ {
+	for i, row := range rows {
 		alignNextLine(r, cursorColumnSpacing.X)
 
-		if i == selected {
-			r.out.SetColor(r.selectedSuggestionTextColor, r.selectedSuggestionBGColor, true)
+		if row.isHeader() {
+			r.writeGroupHeader(row.group, width-1)
 		} else {
-			r.out.SetColor(r.suggestionTextColor, r.suggestionBGColor, false)
-		}
-		if _, err := r.out.WriteString(formatted[i].Text); err != nil {
-			panic(err)
-		}
+			selected := row.index == completions.selected
+			r.writeSuggestionText(formatted[row.index].Text, iconCell(icons, row.index), &suggestions[row.index], query, selected)
 
-		if i == selected {
-			r.out.SetColor(r.selectedDescriptionTextColor, r.selectedDescriptionBGColor, false)
-		} else {
-			r.out.SetColor(r.descriptionTextColor, r.descriptionBGColor, false)
-		}
-		if _, err := r.out.WriteString(formatted[i].Description); err != nil {
-			panic(err)
+			if selected {
+				r.out.SetColor(r.selectedDescriptionTextColor, r.selectedDescriptionBGColor, false)
+			} else {
+				r.out.SetColor(r.descriptionTextColor, r.descriptionBGColor, false)
+			}
+			if _, err := r.out.WriteString(formatted[row.index].Description); err != nil {
+				panic(err)
+			}
 		}
 
 		if isScrollThumb(i) {
@@ -193,6 +230,170 @@ func (r *Renderer) renderCompletion(buf *Buffer, completions *CompletionManager)
 	r.out.SetColor(DefaultColor, DefaultColor, false)
 }
 
+// Writes the header of a group of suggestions filling the given width.
+func (r *Renderer) writeGroupHeader(group string, width istrings.Width) {
+	r.out.SetColor(r.groupHeaderTextColor, r.groupHeaderBGColor, true)
+	header := runewidth.Truncate(leftPrefix+deleteBreakLineCharacters(group), int(width), shortenSuffix)
+	if _, err := r.out.WriteString(runewidth.FillRight(header, int(width))); err != nil {
+		panic(err)
+	}
+}
+
+// Returns the icon cell of the suggestion at the index,
+// an empty string when the suggestions have no icons.
+func iconCell(icons []string, index int) string {
+	if icons == nil {
+		return ""
+	}
+	return icons[index]
+}
+
+// Returns the width available for the grid of suggestions.
+func (r *Renderer) completionGridWidth() istrings.Width {
+	return r.col - 1 // -1 means a width of scrollbar
+}
+
+// Renders the suggestions in the grid layout
+// starting at the first column of the terminal.
+// The rows are split into pages of completions.max rows,
+// the page of the selected suggestion is displayed.
+func (r *Renderer) renderCompletionGrid(buf *Buffer, completions *CompletionManager, suggestions []Suggest, query string) {
+	cells, columns := formatGrid(suggestions, r.completionGridWidth(), r.suggestKindIcons)
+	icons := suggestionIconCells(suggestions, r.suggestKindIcons)
+	if columns == 0 {
+		return
+	}
+	cellWidth := istrings.GetWidth(cells[0])
+	rows := (len(cells) + columns - 1) / columns
+	windowHeight := rows
+	if windowHeight > int(completions.max) {
+		windowHeight = int(completions.max)
+	}
+	if windowHeight == 0 {
+		return
+	}
+	firstRow := 0
+	if completions.selected >= 0 {
+		firstRow = completions.selected / columns / windowHeight * windowHeight
+	}
+	r.prepareArea(windowHeight)
+
+	prefixWidth := istrings.GetWidth(r.prefixCallback())
+	cursor := positionAtEndOfString(buf.Document().TextBeforeCursor(), r.col-prefixWidth)
+	cursor.X += prefixWidth
+
+	fractionVisible := float64(windowHeight) / float64(rows)
+	fractionAbove := float64(firstRow) / float64(rows)
+	scrollbarHeight := int(clamp(float64(windowHeight), 1, float64(windowHeight)*fractionVisible))
+	scrollbarTop := int(float64(windowHeight) * fractionAbove)
+
+	for i := 0; i < windowHeight; i++ {
+		alignNextLine(r, 0)
+
+		for j := 0; j < columns; j++ {
+			index := (firstRow+i)*columns + j
+			if index >= len(cells) {
+				r.out.SetColor(DefaultColor, DefaultColor, false)
+				r.out.CursorForward(int(cellWidth) * (columns - j))
+				break
+			}
+			r.writeSuggestionText(cells[index], iconCell(icons, index), &suggestions[index], query, index == completions.selected)
+		}
+
+		if scrollbarTop <= i && i <= scrollbarTop+scrollbarHeight {
+			r.out.SetColor(DefaultColor, r.scrollbarThumbColor, false)
+		} else {
+			r.out.SetColor(DefaultColor, r.scrollbarBGColor, false)
+		}
+		if _, err := r.out.WriteString(" "); err != nil {
+			panic(err)
+		}
+		r.out.SetColor(DefaultColor, DefaultColor, false)
+	}
+
+	r.out.CursorUp(windowHeight)
+	if _, err := r.out.WriteString("\r"); err != nil {
+		panic(err)
+	}
+	r.out.CursorForward(int(cursor.X))
+	r.out.SetColor(DefaultColor, DefaultColor, false)
+}
+
+// Writes the formatted text of a suggestion
+// highlighting the runes of the displayed text that fuzzy match the query.
+// icon is the icon cell the formatted text starts with.
+func (r *Renderer) writeSuggestionText(formatted, icon string, s *Suggest, query string, selected bool) {
+	fg, matchFg, bg, bold := r.suggestionTextColor, r.suggestionMatchTextColor, r.suggestionBGColor, false
+	if selected {
+		fg, matchFg, bg, bold = r.selectedSuggestionTextColor, r.selectedSuggestionMatchTextColor, r.selectedSuggestionBGColor, true
+	} else {
+		if s.TextColor != DefaultColor {
+			fg = s.TextColor
+		}
+		if s.BGColor != DefaultColor {
+			bg = s.BGColor
+		}
+	}
+
+	r.out.SetColor(fg, bg, bold)
+	highlighted := suggestionMatchHighlights(formatted, icon, s.displayText(), query)
+	if len(highlighted) == 0 {
+		if _, err := r.out.WriteString(formatted); err != nil {
+			panic(err)
+		}
+		return
+	}
+
+	runes := []rune(formatted)
+	start := 0
+	for start < len(runes) {
+		end := start + 1
+		for end < len(runes) && highlighted[end] == highlighted[start] {
+			end++
+		}
+		if highlighted[start] {
+			r.out.SetColor(matchFg, bg, true)
+		} else {
+			r.out.SetColor(fg, bg, bold)
+		}
+		if _, err := r.out.WriteString(string(runes[start:end])); err != nil {
+			panic(err)
+		}
+		start = end
+	}
+	r.out.SetColor(fg, bg, bold)
+}
+
+// Returns a map of the indices of the runes of the formatted suggestion text
+// that are a part of the fuzzy match of the query.
+// Runes hidden by shortening the text are not highlighted.
+func suggestionMatchHighlights(formatted, icon, displayText, query string) map[int]bool {
+	if query == "" {
+		return nil
+	}
+	text := deleteBreakLineCharacters(displayText)
+	_, positions, ok := FuzzyMatch(text, query, true)
+	if !ok || !strings.HasPrefix(formatted, leftPrefix+icon) {
+		return nil
+	}
+
+	offset := len([]rune(leftPrefix + icon))
+	visible := len([]rune(text))
+	if !strings.HasPrefix(formatted, leftPrefix+icon+text) {
+		// the text has been shortened
+		shortened := strings.TrimRight(strings.TrimPrefix(formatted, leftPrefix+icon), " ")
+		visible = len([]rune(shortened)) - len([]rune(shortenSuffix))
+	}
+
+	highlighted := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		if int(p) < visible {
+			highlighted[int(p)+offset] = true
+		}
+	}
+	return highlighted
+}
+
 // Render renders to the console.
 func (r *Renderer) Render(buffer *Buffer, completion *CompletionManager, lexer Lexer) {
 	// In situations where a pseudo tty is allocated (e.g. within a docker container),
@@ -208,14 +409,21 @@ func (r *Renderer) Render(buffer *Buffer, completion *CompletionManager, lexer L
 	prefixWidth := istrings.GetWidth(prefix)
 	col := r.col - prefixWidth
 	endLine := buffer.startLine + int(r.row) - 1
-	cursor := positionAtEndOfStringLine(text, col, endLine)
+	autoSuggestion := fitAutoSuggestion(text, r.autoSuggestion, col)
+	cursor := positionAtEndOfStringLine(text+autoSuggestion, col, endLine)
 	cursor.X += prefixWidth
 
 	// Rendering
 	r.out.HideCursor()
 	defer r.out.ShowCursor()
 
+	if r.selectionStart < r.selectionEnd {
+		lexer = r.selectionLexer(lexer)
+	}
 	r.renderText(lexer, buffer.Text(), buffer.startLine)
+	if autoSuggestion != "" {
+		r.writeStringColor(autoSuggestion, r.autoSuggestionTextColor)
+	}
 
 	r.out.SetColor(DefaultColor, DefaultColor, false)
 
@@ -228,6 +436,16 @@ func (r *Renderer) Render(buffer *Buffer, completion *CompletionManager, lexer L
 	r.previousCursor = cursor
 }
 
+// renderHistorySearch renders the buffer with the prompt
+// of the incremental history search in place of the prefix.
+func (r *Renderer) renderHistorySearch(buffer *Buffer, prompt string, lexer Lexer) {
+	prefixCallback := r.prefixCallback
+	r.prefixCallback = func() string { return prompt }
+	defer func() { r.prefixCallback = prefixCallback }()
+
+	r.Render(buffer, &CompletionManager{}, lexer)
+}
+
 func (r *Renderer) renderText(lexer Lexer, input string, startLine int) {
 	if lexer != nil {
 		r.lex(lexer, input, startLine)
@@ -452,6 +670,72 @@ tokenLoop:
 	r.resetFormatting()
 }
 
+// selectionLexer wraps the given lexer (which may be nil) so that
+// the selected runes keep their colors and get displayed in reverse video.
+// The returned tokens cover the whole input because lex gives the text
+// between tokens the display attributes of the following token.
+func (r *Renderer) selectionLexer(lexer Lexer) Lexer {
+	return NewEagerLexer(func(input string) []Token {
+		runes := []rune(input)
+		start, end := int(r.selectionStart), int(r.selectionEnd)
+		if end > len(runes) {
+			end = len(runes)
+		}
+		if start >= end {
+			start, end = 0, 0
+		}
+		from := istrings.ByteNumber(len(string(runes[:start])))
+		to := istrings.ByteNumber(len(string(runes[:end]))) // exclusive
+
+		var tokens []Token
+		// appends the [first, last] range split at the edges of the selection
+		appendRange := func(first, last istrings.ByteNumber, color, bgColor Color, attrs []DisplayAttribute) {
+			for first <= last {
+				next := last
+				selected := first >= from && first < to
+				switch {
+				case selected && to-1 < last:
+					next = to - 1
+				case !selected && first < from && from <= last:
+					next = from - 1
+				}
+				tokenAttrs := attrs
+				if selected {
+					tokenAttrs = append(append([]DisplayAttribute{}, attrs...), DisplayReverse)
+				}
+				tokens = append(tokens, NewSimpleToken(
+					first,
+					next,
+					SimpleTokenWithColor(color),
+					SimpleTokenWithBackgroundColor(bgColor),
+					SimpleTokenWithDisplayAttributes(tokenAttrs...),
+				))
+				first = next + 1
+			}
+		}
+
+		var next istrings.ByteNumber // first byte not covered by a token yet
+		if lexer != nil {
+			lexer.Init(input)
+			for {
+				token, ok := lexer.Next()
+				if !ok {
+					break
+				}
+				first, last := token.FirstByteIndex(), token.LastByteIndex()
+				if first < next || last < first {
+					continue
+				}
+				appendRange(next, first-1, r.inputTextColor, r.inputBGColor, nil)
+				appendRange(first, last, token.Color(), token.BackgroundColor(), token.DisplayAttributes())
+				next = last + 1
+			}
+		}
+		appendRange(next, istrings.Len(input)-1, r.inputTextColor, r.inputBGColor, nil)
+		return tokens
+	})
+}
+
 func (r *Renderer) resetFormatting() {
 	r.out.SetDisplayAttributes(r.inputTextColor, r.inputBGColor, DisplayReset)
 }
@@ -480,6 +764,17 @@ func (r *Renderer) BreakLine(buffer *Buffer, lexer Lexer) {
 	r.previousCursor = Position{}
 }
 
+// renderMessage writes the message in its own line
+// below the input.
+func (r *Renderer) renderMessage(message string) {
+	r.out.SetColor(DefaultColor, DefaultColor, false)
+	if _, err := r.out.WriteString(message + "\n"); err != nil {
+		panic(err)
+	}
+	r.flush()
+	r.previousCursor = Position{}
+}
+
 // Get the number of columns that are available
 // for user input.
 func (r *Renderer) UserInputColumns() istrings.Width {
@@ -62,7 +62,7 @@ func TestFormatCompletion(t *testing.T) {
 	}
 
 	for _, s := range scenarioTable {
-		ac, width := formatSuggestions(s.completions, s.maxWidth)
+		ac, width := formatSuggestions(s.completions, s.maxWidth, nil)
 		if !reflect.DeepEqual(ac, s.expected) {
 			t.Errorf("Should be %#v, but got %#v", s.expected, ac)
 		}
@@ -139,3 +139,93 @@ func TestGetMultilinePrefix(t *testing.T) {
 		})
 	}
 }
+
+func TestSuggestionMatchHighlights(t *testing.T) {
+	tests := []struct {
+		formatted string
+		icon      string
+		text      string
+		query     string
+		expected  map[int]bool
+	}{
+		{" foo_bar ", "", "foo_bar", "fb", map[int]bool{1: true, 5: true}},
+		{" foo_bar    ", "", "foo_bar", "", nil},
+		{" foo_bar ", "", "foo_bar", "xyz", nil},
+		// the match of "r" is hidden by shortening the text
+		{" foo_... ", "", "foo_bar", "fr", map[int]bool{1: true}},
+		{" f foo_bar ", "f ", "foo_bar", "fb", map[int]bool{3: true, 7: true}},
+	}
+
+	for _, test := range tests {
+		if actual := suggestionMatchHighlights(test.formatted, test.icon, test.text, test.query); !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("%q with %q: should be %#v, but got %#v", test.formatted, test.query, test.expected, actual)
+		}
+	}
+}
+
+func TestSelectionLexer(t *testing.T) {
+	r := NewRenderer()
+	r.selectionStart, r.selectionEnd = 2, 5
+	lexer := NewEagerLexer(func(string) []Token {
+		return []Token{
+			NewSimpleToken(0, 3, SimpleTokenWithColor(Blue), SimpleTokenWithDisplayAttributes(DisplayBold)),
+			NewSimpleToken(7, 8, SimpleTokenWithColor(Red)),
+		}
+	})
+
+	type span struct {
+		first, last istrings.ByteNumber
+		color       Color
+		attrs       []DisplayAttribute
+	}
+	tests := map[string]struct {
+		lexer Lexer
+		input string
+		want  []span
+	}{
+		"without a lexer": {
+			input: "aüc de",
+			want: []span{
+				{0, 2, r.inputTextColor, nil},
+				{3, 5, r.inputTextColor, []DisplayAttribute{DisplayReverse}},
+				{6, 6, r.inputTextColor, nil},
+			},
+		},
+		"tokens split at the edges of the selection": {
+			lexer: lexer,
+			input: "abcdefghi",
+			want: []span{
+				{0, 1, Blue, []DisplayAttribute{DisplayBold}},
+				{2, 3, Blue, []DisplayAttribute{DisplayBold, DisplayReverse}},
+				{4, 4, r.inputTextColor, []DisplayAttribute{DisplayReverse}},
+				{5, 6, r.inputTextColor, nil},
+				{7, 8, Red, nil},
+			},
+		},
+		"selection past the end of the input": {
+			input: "abc",
+			want: []span{
+				{0, 1, r.inputTextColor, nil},
+				{2, 2, r.inputTextColor, []DisplayAttribute{DisplayReverse}},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			l := r.selectionLexer(tc.lexer)
+			l.Init(tc.input)
+			var got []span
+			for {
+				token, ok := l.Next()
+				if !ok {
+					break
+				}
+				got = append(got, span{token.FirstByteIndex(), token.LastByteIndex(), token.Color(), token.DisplayAttributes()})
+			}
+			if !reflect.DeepEqual(tc.want, got) {
+				t.Errorf("Want %#v, but got %#v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package prompt
+
+import (
+	"strings"
+	"unicode"
+
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+// QuoteKind is a kind of quotes of a shell word.
+type QuoteKind uint8
+
+const (
+	// QuoteNone means no quotes, special characters are escaped with a backslash.
+	QuoteNone QuoteKind = iota
+	// QuoteSingle means 'single quotes', nothing gets escaped inside them.
+	QuoteSingle
+	// QuoteDouble means "double quotes", only \", \\, \$ and \` are escaped inside them.
+	QuoteDouble
+)
+
+// characters escaped by a backslash inside double quotes
+const doubleQuoteEscapable = "\"\\$`"
+
+// characters escaped by a backslash outside of quotes
+const shellSpecialCharacters = " \t\n\"'\\$`&;|<>()*?[]{}!#"
+
+// ShellWord is a shell word of the text of a Document.
+type ShellWord struct {
+	// Value is the unescaped text of the word without quotes.
+	Value string
+	// Raw is the text of the word as it has been typed.
+	Raw string
+	// Start and End delimit Raw in the runes of the text.
+	Start istrings.RuneNumber
+	End   istrings.RuneNumber
+	// Index is the index of the word among the words of the text.
+	Index int
+	// Quote is the kind of the quotes left open at the end of the word.
+	Quote QuoteKind
+}
+
+// Returns the kind of quotes text inserted in place of the word should use,
+// the ones left open or the ones the word starts with.
+func (w *ShellWord) quoteStyle() QuoteKind {
+	if w.Quote != QuoteNone {
+		return w.Quote
+	}
+	switch {
+	case strings.HasPrefix(w.Raw, "'"):
+		return QuoteSingle
+	case strings.HasPrefix(w.Raw, `"`):
+		return QuoteDouble
+	}
+	return QuoteNone
+}
+
+// ShellWords splits the text of the document into shell words
+// taking quotes and backslash escapes into account.
+func (d *Document) ShellWords() []ShellWord {
+	return splitShellWords([]rune(d.Text))
+}
+
+// ShellWordsBeforeCursor splits the text before the cursor into shell words.
+// The last word is the one under the cursor (see CurrentShellWord).
+func (d *Document) ShellWordsBeforeCursor() []ShellWord {
+	text := []rune(d.TextBeforeCursor())
+	words := splitShellWords(text)
+	if len(words) == 0 || int(words[len(words)-1].End) < len(text) {
+		// the cursor is after a separator, a new word starts there
+		end := istrings.RuneNumber(len(text))
+		words = append(words, ShellWord{Start: end, End: end, Index: len(words)})
+	}
+	return words
+}
+
+// CurrentShellWord returns the shell word that ends at the cursor.
+// Its Value is unescaped, Start and End are the range of the text
+// that should be replaced by a completion of the word.
+// The word is empty when the cursor is after a separator.
+func (d *Document) CurrentShellWord() ShellWord {
+	words := d.ShellWordsBeforeCursor()
+	return words[len(words)-1]
+}
+
+// Splits the runes into shell words.
+// The last word is unterminated when the text doesn't end with a separator,
+// its Quote is the kind of the quotes left open.
+func splitShellWords(text []rune) []ShellWord {
+	var words []ShellWord
+	var value []rune
+	var quote QuoteKind
+	var escaped, inWord bool
+	start := 0
+
+	for i, r := range text {
+		if !inWord {
+			if unicode.IsSpace(r) {
+				continue
+			}
+			inWord = true
+			start = i
+			value = nil
+		}
+
+		switch {
+		case escaped:
+			if quote == QuoteDouble && !strings.ContainsRune(doubleQuoteEscapable, r) {
+				value = append(value, '\\')
+			}
+			value = append(value, r)
+			escaped = false
+		case quote == QuoteSingle:
+			if r == '\'' {
+				quote = QuoteNone
+			} else {
+				value = append(value, r)
+			}
+		case r == '\\':
+			escaped = true
+		case quote == QuoteDouble:
+			if r == '"' {
+				quote = QuoteNone
+			} else {
+				value = append(value, r)
+			}
+		case r == '\'':
+			quote = QuoteSingle
+		case r == '"':
+			quote = QuoteDouble
+		case unicode.IsSpace(r):
+			words = append(words, ShellWord{
+				Value: string(value),
+				Raw:   string(text[start:i]),
+				Start: istrings.RuneNumber(start),
+				End:   istrings.RuneNumber(i),
+				Index: len(words),
+			})
+			inWord = false
+		default:
+			value = append(value, r)
+		}
+	}
+
+	if inWord {
+		words = append(words, ShellWord{
+			Value: string(value),
+			Raw:   string(text[start:]),
+			Start: istrings.RuneNumber(start),
+			End:   istrings.RuneNumber(len(text)),
+			Index: len(words),
+			Quote: quote,
+		})
+	}
+	return words
+}
+
+// ShellQuote returns the text of a shell word whose value is the given string
+// using the given kind of quotes.
+// Without quotes special characters are escaped with a backslash.
+func ShellQuote(value string, quote QuoteKind) string {
+	var b strings.Builder
+	switch quote {
+	case QuoteSingle:
+		b.WriteByte('\'')
+		b.WriteString(strings.ReplaceAll(value, "'", `'\''`))
+		b.WriteByte('\'')
+	case QuoteDouble:
+		b.WriteByte('"')
+		for _, r := range value {
+			if strings.ContainsRune(doubleQuoteEscapable, r) {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		}
+		b.WriteByte('"')
+	default:
+		for _, r := range value {
+			if strings.ContainsRune(shellSpecialCharacters, r) {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// QuoteSuggestions returns the suggestions with InsertText quoted
+// so that they can replace the raw text of the word (from Start to End).
+// The quotes the word has been started with are kept and closed.
+func QuoteSuggestions(suggestions []Suggest, w ShellWord) []Suggest {
+	quote := w.quoteStyle()
+	result := make([]Suggest, len(suggestions))
+	for i, s := range suggestions {
+		text := s.InsertedText()
+		if quoted := ShellQuote(text, quote); quoted != text {
+			s.InsertText = quoted
+		}
+		result[i] = s
+	}
+	return result
+}
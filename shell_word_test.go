@@ -0,0 +1,122 @@
+package prompt
+
+import (
+	"reflect"
+	"testing"
+
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+func TestDocumentShellWordsBeforeCursor(t *testing.T) {
+	tests := []struct {
+		text string
+		want []ShellWord
+	}{
+		{
+			text: "",
+			want: []ShellWord{{}},
+		},
+		{
+			text: "ls -l ",
+			want: []ShellWord{
+				{Value: "ls", Raw: "ls", Start: 0, End: 2, Index: 0},
+				{Value: "-l", Raw: "-l", Start: 3, End: 5, Index: 1},
+				{Start: 6, End: 6, Index: 2},
+			},
+		},
+		{
+			text: `cat "My Doc`,
+			want: []ShellWord{
+				{Value: "cat", Raw: "cat", Start: 0, End: 3, Index: 0},
+				{Value: "My Doc", Raw: `"My Doc`, Start: 4, End: 11, Index: 1, Quote: QuoteDouble},
+			},
+		},
+		{
+			text: `ls foo\ bar`,
+			want: []ShellWord{
+				{Value: "ls", Raw: "ls", Start: 0, End: 2, Index: 0},
+				{Value: "foo bar", Raw: `foo\ bar`, Start: 3, End: 11, Index: 1},
+			},
+		},
+		{
+			text: `echo 'it'\''s' "a \"b\" \c"x`,
+			want: []ShellWord{
+				{Value: "echo", Raw: "echo", Start: 0, End: 4, Index: 0},
+				{Value: "it's", Raw: `'it'\''s'`, Start: 5, End: 14, Index: 1},
+				{Value: `a "b" \cx`, Raw: `"a \"b\" \c"x`, Start: 15, End: 28, Index: 2},
+			},
+		},
+		{
+			text: "echo 'a b",
+			want: []ShellWord{
+				{Value: "echo", Raw: "echo", Start: 0, End: 4, Index: 0},
+				{Value: "a b", Raw: "'a b", Start: 5, End: 9, Index: 1, Quote: QuoteSingle},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		d := NewDocumentWithText(tt.text)
+		if got := d.ShellWordsBeforeCursor(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: should be %#v, got %#v", tt.text, tt.want, got)
+		}
+		if got, want := d.CurrentShellWord(), tt.want[len(tt.want)-1]; got != want {
+			t.Errorf("%q: should be %#v, got %#v", tt.text, want, got)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		value string
+		quote QuoteKind
+		want  string
+	}{
+		{"foo", QuoteNone, "foo"},
+		{"My Document.txt", QuoteNone, `My\ Document.txt`},
+		{`a"b$c`, QuoteDouble, `"a\"b\$c"`},
+		{"it's", QuoteSingle, `'it'\''s'`},
+	}
+
+	for _, tt := range tests {
+		if got := ShellQuote(tt.value, tt.quote); got != tt.want {
+			t.Errorf("%q: should be %q, got %q", tt.value, tt.want, got)
+		}
+	}
+}
+
+func TestQuoteSuggestions(t *testing.T) {
+	suggestions := []Suggest{{Text: "My Document.txt"}, {Text: "notes.txt"}}
+
+	got := QuoteSuggestions(suggestions, NewDocumentWithText(`cat "My`).CurrentShellWord())
+	want := []Suggest{
+		{Text: "My Document.txt", InsertText: `"My Document.txt"`},
+		{Text: "notes.txt", InsertText: `"notes.txt"`},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Should be %#v, got %#v", want, got)
+	}
+
+	got = QuoteSuggestions(suggestions, NewDocumentWithText("cat My").CurrentShellWord())
+	want = []Suggest{
+		{Text: "My Document.txt", InsertText: `My\ Document.txt`},
+		{Text: "notes.txt"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Should be %#v, got %#v", want, got)
+	}
+}
+
+func TestPromptCompletionQuotesInsertedText(t *testing.T) {
+	p := newTestPrompt(WithCompleter(func(d Document) ([]Suggest, istrings.RuneNumber, istrings.RuneNumber) {
+		w := d.CurrentShellWord()
+		suggestions := FilterHasPrefix([]Suggest{{Text: "My Document.txt"}}, w.Value, false)
+		return QuoteSuggestions(suggestions, w), w.Start, w.End
+	}))
+	feedAll(p, `cat "My`)
+	p.completion.Update(*p.buffer.Document())
+	feedAll(p, "\t")
+	if got, want := p.buffer.Text(), `cat "My Document.txt"`; got != want {
+		t.Errorf("Want %q, but got %q", want, got)
+	}
+}
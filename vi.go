@@ -0,0 +1,860 @@
+package prompt
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+/*
+
+========
+PROGRESS
+========
+
+Modes
+-----
+
+* [x] Esc        Leave insert/replace/visual mode and enter normal mode
+* [x] i, a       Insert before/after the cursor
+* [x] I, A       Insert at the start/end of the line
+* [x] R          Enter replace mode
+* [x] v          Toggle visual mode
+
+Motions (accept a count)
+------------------------
+
+* [x] h, l       Backward/forward one character
+* [x] j, k       Down/up one line or through the history
+* [x] w, b, e    Start of the next word, start of the previous word, end of the word
+* [x] W, B, E    Same as above but for whitespace separated WORDs
+* [x] 0, ^, $    Start of the line, first non-blank character, end of the line
+* [x] f, t       Forward to/till the given character
+* [x] F, T       Backward to/till the given character
+* [x] ;, ,       Repeat the last f, t, F, T in the same/opposite direction
+
+Operators (accept a count)
+--------------------------
+
+* [x] d, c, y    Delete, change, yank with a motion (dd, cc, yy operate on the whole line)
+* [x] D, C, Y    Delete/change to the end of the line, yank the whole line
+* [x] x, X       Delete the character under/before the cursor
+* [x] s, S       Substitute the character under the cursor/the whole line
+* [x] r          Replace the character under the cursor
+* [x] p, P       Paste after/before the cursor
+* [x] ~          Toggle the case of the character under the cursor
+* [x] u, Ctrl+r  Undo, redo
+
+*/
+
+// ViMode represents the state of the vi key bindings.
+type ViMode uint8
+
+const (
+	// ViInsert is the mode in which typed characters are inserted into the buffer.
+	ViInsert ViMode = iota
+	// ViNormal is the mode in which keys are interpreted as motions and operators.
+	ViNormal
+	// ViVisual is the mode in which motions extend a selection.
+	ViVisual
+	// ViReplace is the mode in which typed characters overwrite the buffer.
+	ViReplace
+)
+
+// String returns the name of the mode.
+func (m ViMode) String() string {
+	switch m {
+	case ViInsert:
+		return "insert"
+	case ViNormal:
+		return "normal"
+	case ViVisual:
+		return "visual"
+	case ViReplace:
+		return "replace"
+	default:
+		return "unknown"
+	}
+}
+
+// ViModePrefixCallback returns a prompt prefix for the given vi mode.
+type ViModePrefixCallback func(mode ViMode) (prefix string)
+
+// State of the vi key bindings.
+type viState struct {
+	mode         ViMode
+	count        int  // count typed before a motion or command
+	opCount      int  // count typed before the pending operator
+	operator     rune // pending operator: 'd', 'c', 'y' or 0
+	pendingChar  rune // command waiting for a character argument: 'f', 'F', 't', 'T', 'r' or 0
+	lastFind     rune // last executed 'f', 'F', 't' or 'T'
+	lastFindChar rune
+	visualStart  istrings.RuneNumber
+	register     string
+	linewise     bool // whether the register holds whole lines
+}
+
+func (v *viState) clearPending() {
+	v.count = 0
+	v.opCount = 0
+	v.operator = 0
+	v.pendingChar = 0
+}
+
+// Returns the count for the current command (at least 1).
+func (v *viState) takeCount() int {
+	count := v.count
+	if count == 0 {
+		count = 1
+	}
+	if v.opCount > 0 {
+		count *= v.opCount
+	}
+	v.count = 0
+	v.opCount = 0
+	return count
+}
+
+// ViMode returns the current mode of the vi key bindings.
+// It is always ViInsert when the prompt doesn't use ViKeyBind.
+func (p *Prompt) ViMode() ViMode {
+	if p.keyBindMode != ViKeyBind {
+		return ViInsert
+	}
+	return p.vi.mode
+}
+
+// Puts the vi key bindings back into the initial state.
+func (p *Prompt) resetViState() {
+	p.vi.clearPending()
+	p.vi.mode = ViInsert
+}
+
+// handleViKey processes keys that are interpreted by the vi key bindings.
+// Returns true in handled when the key should not be processed any further.
+func (p *Prompt) handleViKey(b []byte, key Key) (handled bool) {
+	v := &p.vi
+	switch v.mode {
+	case ViInsert, ViReplace:
+		if key != Escape {
+			return false
+		}
+		v.mode = ViNormal
+		if p.buffer.Document().CurrentLineBeforeCursor() != "" {
+			p.buffer.CursorLeft(1, p.renderer.UserInputColumns(), p.renderer.row)
+		}
+		p.completion.Reset()
+		return true
+	}
+
+	switch key {
+	case Escape:
+		v.clearPending()
+		if v.mode == ViVisual {
+			v.mode = ViNormal
+		}
+		return true
+	case Backspace:
+		b = []byte{'h'}
+	case ControlR:
+		v.clearPending()
+		p.Redo()
+		p.viClampCursor()
+		return true
+	case NotDefined:
+	default:
+		v.clearPending()
+		return false
+	}
+
+	for len(b) > 0 {
+		char, size := utf8.DecodeRune(b)
+		b = b[size:]
+		if char == 'u' && v.pendingChar == 0 {
+			p.viNormalChar(char)
+		} else {
+			// every other command can be undone in a single step
+			p.buffer.beginEdit(editOther)
+			p.viNormalChar(char)
+			p.buffer.endEdit()
+		}
+		if len(b) > 0 && (v.mode == ViInsert || v.mode == ViReplace) {
+			// the rest of the input has been typed in the insert mode
+			p.buffer.InsertTextMoveCursor(string(b), p.renderer.UserInputColumns(), p.renderer.row, v.mode == ViReplace)
+			break
+		}
+	}
+	p.completion.Reset()
+	return true
+}
+
+// Executes a character typed in the normal or visual mode.
+func (p *Prompt) viNormalChar(char rune) {
+	v := &p.vi
+	if v.pendingChar != 0 {
+		cmd := v.pendingChar
+		v.pendingChar = 0
+		if cmd == 'r' {
+			p.viReplaceChars(char, v.takeCount())
+			return
+		}
+		v.lastFind = cmd
+		v.lastFindChar = char
+		p.viFind(cmd, char, false)
+		return
+	}
+
+	if char >= '1' && char <= '9' || char == '0' && v.count > 0 {
+		v.count = v.count*10 + int(char-'0')
+		return
+	}
+
+	if v.mode == ViVisual {
+		switch char {
+		case 'd', 'x', 'c', 's', 'y':
+			p.viVisualOperator(char)
+			return
+		case 'v':
+			v.clearPending()
+			v.mode = ViNormal
+			return
+		}
+	}
+
+	switch char {
+	// motions
+	case ' ':
+		p.viMotion('l')
+	case 'h', 'l', 'w', 'W', 'b', 'B', 'e', 'E', '0', '^', '$':
+		p.viMotion(char)
+	case 'f', 'F', 't', 'T':
+		v.pendingChar = char
+	case ';', ',':
+		if v.lastFind == 0 {
+			v.clearPending()
+			return
+		}
+		cmd := v.lastFind
+		if char == ',' {
+			cmd = viReverseFind(cmd)
+		}
+		p.viFind(cmd, v.lastFindChar, true)
+	case 'j', 'k':
+		count := v.takeCount()
+		v.operator = 0
+		if char == 'k' {
+			count = -count
+		}
+		p.viVerticalMove(count)
+
+	// operators
+	case 'd', 'c', 'y':
+		if v.operator == char {
+			p.viLineOperator(char)
+			return
+		}
+		if v.operator != 0 {
+			v.clearPending()
+			return
+		}
+		v.operator = char
+		v.opCount = v.count
+		v.count = 0
+	case 'D':
+		v.operator = 'd'
+		p.viMotion('$')
+	case 'C':
+		v.operator = 'c'
+		p.viMotion('$')
+	case 'Y':
+		p.viLineOperator('y')
+	case 'x':
+		v.operator = 'd'
+		p.viMotion('l')
+	case 'X':
+		v.operator = 'd'
+		p.viMotion('h')
+	case 's':
+		v.operator = 'c'
+		p.viMotion('l')
+	case 'S':
+		p.viLineOperator('c')
+	case 'r':
+		v.pendingChar = 'r'
+	case 'p':
+		p.viPaste(true, v.takeCount())
+	case 'P':
+		p.viPaste(false, v.takeCount())
+	case '~':
+		p.viToggleCase(v.takeCount())
+	case 'u':
+		for count := v.takeCount(); count > 0 && p.Undo(); count-- {
+		}
+		p.viClampCursor()
+
+	// mode changes
+	case 'i':
+		p.viEnterInsert(ViInsert)
+	case 'a':
+		if p.buffer.Document().CurrentLineAfterCursor() != "" {
+			p.viSetCursor(p.buffer.cursorPosition + 1)
+		}
+		p.viEnterInsert(ViInsert)
+	case 'I':
+		text := []rune(p.buffer.Text())
+		p.viSetCursor(istrings.RuneNumber(viFirstNonBlank(text, int(p.buffer.cursorPosition))))
+		p.viEnterInsert(ViInsert)
+	case 'A':
+		text := []rune(p.buffer.Text())
+		_, end := viLineBounds(text, int(p.buffer.cursorPosition))
+		p.viSetCursor(istrings.RuneNumber(end))
+		p.viEnterInsert(ViInsert)
+	case 'R':
+		p.viEnterInsert(ViReplace)
+	case 'v':
+		v.clearPending()
+		v.mode = ViVisual
+		v.visualStart = p.buffer.cursorPosition
+	default:
+		v.clearPending()
+	}
+}
+
+func (p *Prompt) viEnterInsert(mode ViMode) {
+	p.vi.clearPending()
+	p.vi.mode = mode
+}
+
+// Executes a motion, applying the pending operator if there is one.
+func (p *Prompt) viMotion(motion rune) {
+	v := &p.vi
+	count := v.takeCount()
+	text := []rune(p.buffer.Text())
+	pos := int(p.buffer.cursorPosition)
+	lineStart, lineEnd := viLineBounds(text, pos)
+
+	var target int
+	var inclusive bool
+	switch motion {
+	case 'h':
+		target = pos - count
+		if target < lineStart {
+			target = lineStart
+		}
+	case 'l':
+		target = pos + count
+		if target > lineEnd {
+			target = lineEnd
+		}
+	case '0':
+		target = lineStart
+	case '^':
+		target = viFirstNonBlank(text, pos)
+	case '$':
+		target = lineEnd
+	case 'w', 'W':
+		target = pos
+		// "cw" behaves like "ce" when the cursor is on a word
+		if v.operator == 'c' && pos < len(text) && !unicode.IsSpace(text[pos]) {
+			for i := 0; i < count; i++ {
+				if i == 0 && viIsWordEnd(text, target, motion == 'W') {
+					continue
+				}
+				target = viWordEndForward(text, target, motion == 'W')
+			}
+			inclusive = true
+			break
+		}
+		var last int // start of the last word moved over
+		for i := 0; i < count; i++ {
+			last = target
+			target = viWordStartForward(text, target, motion == 'W')
+		}
+		// operators stop at the end of the line of the last word moved over
+		if v.operator != 0 {
+			if _, end := viLineBounds(text, last); target > end {
+				target = end
+			}
+		}
+	case 'b', 'B':
+		target = pos
+		for i := 0; i < count; i++ {
+			target = viWordStartBackward(text, target, motion == 'B')
+		}
+	case 'e', 'E':
+		target = pos
+		for i := 0; i < count; i++ {
+			target = viWordEndForward(text, target, motion == 'E')
+		}
+		inclusive = true
+	}
+
+	p.viApply(text, pos, target, inclusive)
+}
+
+// Moves to the given character on the current line.
+// When the find gets repeated with ';' or ',', 't' and 'T' skip
+// the character next to the cursor so that they don't stay in place.
+func (p *Prompt) viFind(cmd, char rune, repeat bool) {
+	v := &p.vi
+	count := v.takeCount()
+	text := []rune(p.buffer.Text())
+	pos := int(p.buffer.cursorPosition)
+	lineStart, lineEnd := viLineBounds(text, pos)
+
+	target := pos
+	var inclusive bool
+	switch cmd {
+	case 'f', 't':
+		inclusive = true
+		i := pos
+		if cmd == 't' && repeat {
+			i++
+		}
+		for n := 0; n < count; n++ {
+			i++
+			for i < lineEnd && text[i] != char {
+				i++
+			}
+			if i >= lineEnd {
+				v.clearPending()
+				return
+			}
+		}
+		target = i
+		if cmd == 't' {
+			target--
+		}
+	case 'F', 'T':
+		i := pos
+		if cmd == 'T' && repeat {
+			i--
+		}
+		for n := 0; n < count; n++ {
+			i--
+			for i >= lineStart && text[i] != char {
+				i--
+			}
+			if i < lineStart {
+				v.clearPending()
+				return
+			}
+		}
+		target = i
+		if cmd == 'T' {
+			target++
+		}
+	}
+
+	p.viApply(text, pos, target, inclusive)
+}
+
+// Moves the cursor to target or applies the pending operator
+// (or the visual selection) to the text between pos and target.
+func (p *Prompt) viApply(text []rune, pos, target int, inclusive bool) {
+	v := &p.vi
+	operator := v.operator
+	v.operator = 0
+	if operator == 0 {
+		p.viSetCursor(istrings.RuneNumber(target))
+		p.viClampCursor()
+		return
+	}
+
+	start, end := pos, target
+	if start > end {
+		start, end = end, start
+	}
+	if inclusive && end < len(text) {
+		end++
+	}
+	p.viOperate(operator, start, end, false)
+}
+
+// Applies an operator to the runes in the [start, end) range.
+func (p *Prompt) viOperate(operator rune, start, end int, linewise bool) {
+	v := &p.vi
+	text := []rune(p.buffer.Text())
+	if end > len(text) {
+		end = len(text)
+	}
+	if start > end {
+		start = end
+	}
+	if start < end || linewise {
+		v.register = string(text[start:end])
+		v.linewise = linewise
+	}
+
+	switch operator {
+	case 'y':
+		p.viSetCursor(istrings.RuneNumber(start))
+		v.mode = ViNormal
+		p.viClampCursor()
+	case 'd':
+		p.viDeleteRange(start, end)
+		v.mode = ViNormal
+		p.viClampCursor()
+	case 'c':
+		p.viDeleteRange(start, end)
+		p.viEnterInsert(ViInsert)
+	}
+}
+
+// Applies an operator to count whole lines (dd, cc, yy).
+func (p *Prompt) viLineOperator(operator rune) {
+	v := &p.vi
+	v.operator = 0
+	count := v.takeCount()
+	text := []rune(p.buffer.Text())
+	start, end := viLineBounds(text, int(p.buffer.cursorPosition))
+	for i := 1; i < count && end < len(text); i++ {
+		_, end = viLineBounds(text, end+1)
+	}
+
+	if operator == 'c' {
+		// keep the line itself and its indentation
+		p.viOperate(operator, viFirstNonBlank(text, start), end, false)
+		return
+	}
+
+	p.viOperate('y', start, end, true)
+	if operator != 'd' {
+		return
+	}
+	// also remove one of the line breaks surrounding the deleted lines
+	if end < len(text) {
+		end++
+	} else if start > 0 {
+		start--
+	}
+	p.viDeleteRange(start, end)
+	text = []rune(p.buffer.Text())
+	p.viSetCursor(istrings.RuneNumber(viFirstNonBlank(text, start)))
+	p.viClampCursor()
+}
+
+// Applies an operator to the visual selection.
+func (p *Prompt) viVisualOperator(operator rune) {
+	p.vi.clearPending()
+	start, end := p.viSelection()
+	switch operator {
+	case 'x':
+		operator = 'd'
+	case 's':
+		operator = 'c'
+	}
+	p.viOperate(operator, int(start), int(end), false)
+}
+
+// Returns the [start, end) range of the visual selection,
+// which is empty outside of the visual mode.
+func (p *Prompt) viSelection() (start, end istrings.RuneNumber) {
+	if p.vi.mode != ViVisual {
+		return 0, 0
+	}
+	start, end = p.vi.visualStart, p.buffer.cursorPosition
+	if start > end {
+		start, end = end, start
+	}
+	if length := istrings.RuneCountInString(p.buffer.Text()); end < length {
+		end++
+	}
+	return start, end
+}
+
+// Pastes the register after or before the cursor.
+func (p *Prompt) viPaste(after bool, count int) {
+	v := &p.vi
+	v.clearPending()
+	if v.register == "" {
+		return
+	}
+	cols := p.renderer.UserInputColumns()
+	rows := p.renderer.row
+	text := []rune(p.buffer.Text())
+	pos := int(p.buffer.cursorPosition)
+	pasted := strings.Repeat(v.register, count)
+
+	if v.linewise {
+		lineStart, lineEnd := viLineBounds(text, pos)
+		if after {
+			p.viSetCursor(istrings.RuneNumber(lineEnd))
+			p.buffer.InsertTextMoveCursor("\n"+strings.TrimSuffix(strings.Repeat(v.register+"\n", count), "\n"), cols, rows, false)
+			p.viSetCursor(istrings.RuneNumber(lineEnd + 1))
+		} else {
+			p.viSetCursor(istrings.RuneNumber(lineStart))
+			p.buffer.InsertTextMoveCursor(strings.Repeat(v.register+"\n", count), cols, rows, false)
+			p.viSetCursor(istrings.RuneNumber(lineStart))
+		}
+		return
+	}
+
+	if after && pos < len(text) && text[pos] != '\n' {
+		p.viSetCursor(istrings.RuneNumber(pos + 1))
+	}
+	p.buffer.InsertTextMoveCursor(pasted, cols, rows, false)
+	p.viSetCursor(p.buffer.cursorPosition - 1)
+	p.viClampCursor()
+}
+
+// Replaces count characters under the cursor with char.
+func (p *Prompt) viReplaceChars(char rune, count int) {
+	text := []rune(p.buffer.Text())
+	pos := int(p.buffer.cursorPosition)
+	_, lineEnd := viLineBounds(text, pos)
+	if pos+count > lineEnd {
+		return
+	}
+	p.viDeleteRange(pos, pos+count)
+	p.buffer.InsertTextMoveCursor(
+		strings.Repeat(string(char), count),
+		p.renderer.UserInputColumns(),
+		p.renderer.row,
+		false,
+	)
+	p.viSetCursor(p.buffer.cursorPosition - 1)
+}
+
+// Toggles the case of count characters under the cursor and moves past them.
+func (p *Prompt) viToggleCase(count int) {
+	text := []rune(p.buffer.Text())
+	pos := int(p.buffer.cursorPosition)
+	_, lineEnd := viLineBounds(text, pos)
+	end := pos + count
+	if end > lineEnd {
+		end = lineEnd
+	}
+	if pos >= end {
+		return
+	}
+
+	toggled := make([]rune, 0, end-pos)
+	for _, char := range text[pos:end] {
+		if unicode.IsUpper(char) {
+			toggled = append(toggled, unicode.ToLower(char))
+		} else {
+			toggled = append(toggled, unicode.ToUpper(char))
+		}
+	}
+	p.viDeleteRange(pos, end)
+	p.buffer.InsertTextMoveCursor(string(toggled), p.renderer.UserInputColumns(), p.renderer.row, false)
+	p.viClampCursor()
+}
+
+// Moves the cursor between lines or walks through the history
+// when there are no more lines in the given direction.
+func (p *Prompt) viVerticalMove(count int) {
+	cols := p.renderer.UserInputColumns()
+	rows := p.renderer.row
+	doc := p.buffer.Document()
+	row := int(doc.CursorPositionRow())
+
+	switch {
+	case count < 0 && row > 0:
+		p.buffer.CursorUp(-count, cols, rows)
+	case count > 0 && row < int(doc.TextEndPositionRow()):
+		p.buffer.CursorDown(count, cols, rows)
+	case count < 0:
+		if newBuf, changed := p.history.Older(p.buffer, cols, rows); changed {
+			p.buffer = newBuf
+		}
+	default:
+		if newBuf, changed := p.history.Newer(p.buffer, cols, rows); changed {
+			p.buffer = newBuf
+		}
+	}
+	p.viClampCursor()
+}
+
+// Sets the absolute position of the cursor.
+func (p *Prompt) viSetCursor(pos istrings.RuneNumber) {
+	delta := pos - p.buffer.cursorPosition
+	cols := p.renderer.UserInputColumns()
+	rows := p.renderer.row
+	if delta > 0 {
+		p.buffer.CursorRightRunes(delta, cols, rows)
+	} else if delta < 0 {
+		p.buffer.CursorLeftRunes(-delta, cols, rows)
+	}
+}
+
+// In the normal mode the cursor can't be placed after the last character of the line.
+func (p *Prompt) viClampCursor() {
+	if p.vi.mode == ViInsert || p.vi.mode == ViReplace {
+		return
+	}
+	text := []rune(p.buffer.Text())
+	pos := int(p.buffer.cursorPosition)
+	lineStart, lineEnd := viLineBounds(text, pos)
+	if pos >= lineEnd && lineEnd > lineStart {
+		p.viSetCursor(istrings.RuneNumber(lineEnd - 1))
+	}
+}
+
+// Deletes the runes in the [start, end) range and places the cursor at start.
+func (p *Prompt) viDeleteRange(start, end int) {
+	p.viSetCursor(istrings.RuneNumber(start))
+	if end > start {
+		p.buffer.DeleteRunes(istrings.RuneNumber(end-start), p.renderer.UserInputColumns(), p.renderer.row)
+	}
+}
+
+func viReverseFind(cmd rune) rune {
+	switch cmd {
+	case 'f':
+		return 'F'
+	case 'F':
+		return 'f'
+	case 't':
+		return 'T'
+	default:
+		return 't'
+	}
+}
+
+// Returns the start and end (exclusive, pointing at the line break or the end of the text)
+// of the line that contains pos.
+func viLineBounds(text []rune, pos int) (start, end int) {
+	if pos > len(text) {
+		pos = len(text)
+	}
+	start = pos
+	for start > 0 && text[start-1] != '\n' {
+		start--
+	}
+	end = pos
+	for end < len(text) && text[end] != '\n' {
+		end++
+	}
+	return start, end
+}
+
+// Returns the index of the first non-blank character on the line that contains pos.
+func viFirstNonBlank(text []rune, pos int) int {
+	start, end := viLineBounds(text, pos)
+	i := start
+	for i < end && (text[i] == ' ' || text[i] == '\t') {
+		i++
+	}
+	if i == end && end > start {
+		return end - 1
+	}
+	return i
+}
+
+const (
+	viClassSpace = iota
+	viClassWord
+	viClassPunctuation
+)
+
+// Returns the class of the character used by word motions.
+// When bigWord is true every non-blank character belongs to the same class.
+func viCharClass(char rune, bigWord bool) int {
+	switch {
+	case unicode.IsSpace(char):
+		return viClassSpace
+	case bigWord, char == '_', unicode.IsLetter(char), unicode.IsDigit(char):
+		return viClassWord
+	default:
+		return viClassPunctuation
+	}
+}
+
+func viIsWordEnd(text []rune, pos int, bigWord bool) bool {
+	if pos >= len(text) {
+		return false
+	}
+	class := viCharClass(text[pos], bigWord)
+	return class != viClassSpace && (pos+1 >= len(text) || viCharClass(text[pos+1], bigWord) != class)
+}
+
+// Returns the index of the start of the next word.
+func viWordStartForward(text []rune, pos int, bigWord bool) int {
+	if pos >= len(text) {
+		return len(text)
+	}
+	i := pos
+	class := viCharClass(text[i], bigWord)
+	if class != viClassSpace {
+		for i < len(text) && viCharClass(text[i], bigWord) == class {
+			i++
+		}
+	}
+	for i < len(text) && viCharClass(text[i], bigWord) == viClassSpace {
+		// an empty line counts as a word
+		if text[i] == '\n' && i+1 < len(text) && text[i+1] == '\n' {
+			return i + 1
+		}
+		i++
+	}
+	return i
+}
+
+// Returns the index of the start of the previous word.
+func viWordStartBackward(text []rune, pos int, bigWord bool) int {
+	i := pos - 1
+	for i >= 0 && viCharClass(text[i], bigWord) == viClassSpace {
+		i--
+	}
+	if i < 0 {
+		return 0
+	}
+	class := viCharClass(text[i], bigWord)
+	for i > 0 && viCharClass(text[i-1], bigWord) == class {
+		i--
+	}
+	return i
+}
+
+// Returns the index of the last character of the next word end.
+func viWordEndForward(text []rune, pos int, bigWord bool) int {
+	i := pos + 1
+	for i < len(text) && viCharClass(text[i], bigWord) == viClassSpace {
+		i++
+	}
+	if i >= len(text) {
+		if len(text) == 0 {
+			return 0
+		}
+		return len(text) - 1
+	}
+	class := viCharClass(text[i], bigWord)
+	for i+1 < len(text) && viCharClass(text[i+1], bigWord) == class {
+		i++
+	}
+	return i
+}
+
+var viInsertKeyBindings = []KeyBind{
+	// Delete the word before the cursor
+	{
+		Key: ControlW,
+		Fn:  DeleteWordBeforeCursor,
+	},
+	// Delete the line before the cursor
+	{
+		Key: ControlU,
+		Fn: func(p *Prompt) bool {
+			p.buffer.DeleteBeforeCursorRunes(
+				istrings.RuneCountInString(p.buffer.Document().CurrentLineBeforeCursor()),
+				p.renderer.col,
+				p.renderer.row,
+			)
+			return true
+		},
+	},
+	// Backspace
+	{
+		Key: ControlH,
+		Fn:  DeleteBeforeChar,
+	},
+	// Search the history backwards
+	{
+		Key: ControlR,
+		Fn:  ReverseSearchHistory,
+	},
+}
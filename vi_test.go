@@ -0,0 +1,228 @@
+package prompt
+
+import (
+	"testing"
+
+	istrings "github.com/plandex-ai/go-prompt/strings"
+)
+
+func TestViKeyBindings(t *testing.T) {
+	tests := map[string]struct {
+		text       string
+		keys       []string
+		wantText   string
+		wantCursor istrings.RuneNumber
+		wantMode   ViMode
+	}{
+		"escape enters the normal mode": {
+			text:       "foo bar",
+			keys:       []string{"\x1b"},
+			wantText:   "foo bar",
+			wantCursor: 6,
+			wantMode:   ViNormal,
+		},
+		"move to the start of the line and forward by words": {
+			text:       "foo bar baz",
+			keys:       []string{"\x1b", "0", "2w"},
+			wantText:   "foo bar baz",
+			wantCursor: 8,
+			wantMode:   ViNormal,
+		},
+		"move backward by words": {
+			text:       "foo.bar baz",
+			keys:       []string{"\x1b", "b", "b"},
+			wantText:   "foo.bar baz",
+			wantCursor: 4,
+			wantMode:   ViNormal,
+		},
+		"move to the end of the word": {
+			text:       "foo bar",
+			keys:       []string{"\x1b", "0", "e", "e"},
+			wantText:   "foo bar",
+			wantCursor: 6,
+			wantMode:   ViNormal,
+		},
+		"find a character": {
+			text:       "a,b,c,d",
+			keys:       []string{"\x1b", "0", "f,", ";", "t,"},
+			wantText:   "a,b,c,d",
+			wantCursor: 4,
+			wantMode:   ViNormal,
+		},
+		"find a character till an adjacent target": {
+			text:       "ab",
+			keys:       []string{"\x1b", "0", "dtb"},
+			wantText:   "b",
+			wantCursor: 0,
+			wantMode:   ViNormal,
+		},
+		"find a character backward till an adjacent target": {
+			text:       "abc",
+			keys:       []string{"\x1b", "Tb"},
+			wantText:   "abc",
+			wantCursor: 2,
+			wantMode:   ViNormal,
+		},
+		"repeat a find till a character": {
+			text:       "a,b,c",
+			keys:       []string{"\x1b", "0", "t,", ";"},
+			wantText:   "a,b,c",
+			wantCursor: 2,
+			wantMode:   ViNormal,
+		},
+		"delete words with counts": {
+			text:       "one two three four",
+			keys:       []string{"\x1b", "0", "2d", "w"},
+			wantText:   "three four",
+			wantCursor: 0,
+			wantMode:   ViNormal,
+		},
+		"delete to the end of the line": {
+			text:       "foo bar",
+			keys:       []string{"\x1b", "0", "w", "D"},
+			wantText:   "foo ",
+			wantCursor: 3,
+			wantMode:   ViNormal,
+		},
+		"change a word": {
+			text:       "foo bar",
+			keys:       []string{"\x1b", "0", "cw", "baz"},
+			wantText:   "baz bar",
+			wantCursor: 3,
+			wantMode:   ViInsert,
+		},
+		"move forward by words across lines": {
+			text:       "foo\n\nbar",
+			keys:       []string{"\x1b", "k", "k", "0", "w", "w"},
+			wantText:   "foo\n\nbar",
+			wantCursor: 5,
+			wantMode:   ViNormal,
+		},
+		"delete the last word of a line": {
+			text:       "foo bar\nbaz",
+			keys:       []string{"\x1b", "k", "$", "b", "dw"},
+			wantText:   "foo \nbaz",
+			wantCursor: 3,
+			wantMode:   ViNormal,
+		},
+		"change the blanks at the end of a line": {
+			text:       "foo  \nbar",
+			keys:       []string{"\x1b", "k", "0", "e", "l", "cw", "!"},
+			wantText:   "foo!\nbar",
+			wantCursor: 4,
+			wantMode:   ViInsert,
+		},
+		"delete a line": {
+			text:       "foo bar",
+			keys:       []string{"\x1b", "dd"},
+			wantText:   "",
+			wantCursor: 0,
+			wantMode:   ViNormal,
+		},
+		"yank and paste": {
+			text:       "foo bar",
+			keys:       []string{"\x1b", "0", "yw", "$", "p"},
+			wantText:   "foo barfoo ",
+			wantCursor: 10,
+			wantMode:   ViNormal,
+		},
+		"delete characters": {
+			text:       "abcdef",
+			keys:       []string{"\x1b", "0", "2x", "X"},
+			wantText:   "cdef",
+			wantCursor: 0,
+			wantMode:   ViNormal,
+		},
+		"replace characters": {
+			text:       "abc",
+			keys:       []string{"\x1b", "0", "rx", "l", "Ryz"},
+			wantText:   "xyz",
+			wantCursor: 3,
+			wantMode:   ViReplace,
+		},
+		"append at the end of the line": {
+			text:       "foo",
+			keys:       []string{"\x1b", "0", "A", "!"},
+			wantText:   "foo!",
+			wantCursor: 4,
+			wantMode:   ViInsert,
+		},
+		"delete the visual selection": {
+			text:       "foo bar baz",
+			keys:       []string{"\x1b", "0", "w", "v", "e", "d"},
+			wantText:   "foo  baz",
+			wantCursor: 4,
+			wantMode:   ViNormal,
+		},
+		"undo and redo a command": {
+			text:       "foo bar",
+			keys:       []string{"\x1b", "0", "dw", "dw", "u", string([]byte{0x12}), "u"},
+			wantText:   "bar",
+			wantCursor: 0,
+			wantMode:   ViNormal,
+		},
+		"toggle case": {
+			text:       "abc",
+			keys:       []string{"\x1b", "0", "2~"},
+			wantText:   "ABc",
+			wantCursor: 2,
+			wantMode:   ViNormal,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := newTestPrompt(WithKeyBindMode(ViKeyBind))
+			feedAll(p, tc.text)
+			feedAll(p, tc.keys...)
+
+			if got := p.buffer.Text(); got != tc.wantText {
+				t.Errorf("Want text %q, but got %q", tc.wantText, got)
+			}
+			if got := p.buffer.cursorPosition; got != tc.wantCursor {
+				t.Errorf("Want cursor %d, but got %d", tc.wantCursor, got)
+			}
+			if got := p.ViMode(); got != tc.wantMode {
+				t.Errorf("Want mode %s, but got %s", tc.wantMode, got)
+			}
+		})
+	}
+}
+
+func TestViModePrefixCallback(t *testing.T) {
+	p := newTestPrompt(
+		WithKeyBindMode(ViKeyBind),
+		WithViModePrefixCallback(func(mode ViMode) string {
+			if mode == ViInsert {
+				return "[I] "
+			}
+			return "[N] "
+		}),
+	)
+	if got := p.renderer.prefixCallback(); got != "[I] " {
+		t.Errorf("Want %q, but got %q", "[I] ", got)
+	}
+	feedAll(p, "\x1b")
+	if got := p.renderer.prefixCallback(); got != "[N] " {
+		t.Errorf("Want %q, but got %q", "[N] ", got)
+	}
+	feedAll(p, "i")
+	if got := p.renderer.prefixCallback(); got != "[I] " {
+		t.Errorf("Want %q, but got %q", "[I] ", got)
+	}
+}
+
+func TestViVisualSelection(t *testing.T) {
+	p := newTestPrompt(WithKeyBindMode(ViKeyBind))
+	feedAll(p, "foo bar baz", "\x1b", "b", "v", "b")
+	p.render()
+	if p.renderer.selectionStart != 4 || p.renderer.selectionEnd != 9 {
+		t.Errorf("Want selection 4-9, but got %d-%d", p.renderer.selectionStart, p.renderer.selectionEnd)
+	}
+
+	feedAll(p, "\x1b")
+	p.render()
+	if p.renderer.selectionStart != p.renderer.selectionEnd {
+		t.Errorf("Want no selection, but got %d-%d", p.renderer.selectionStart, p.renderer.selectionEnd)
+	}
+}